@@ -0,0 +1,71 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BlobStorageReclaimPolicy determines what CRO does with the underlying
+// bucket when the owning BlobStorage CR is deleted
+type BlobStorageReclaimPolicy string
+
+const (
+	// BlobStorageReclaimPolicyDelete deletes the bucket when the BlobStorage CR is deleted. This is the default.
+	BlobStorageReclaimPolicyDelete BlobStorageReclaimPolicy = "Delete"
+	// BlobStorageReclaimPolicyRetain leaves the bucket in place when the BlobStorage CR is deleted
+	BlobStorageReclaimPolicyRetain BlobStorageReclaimPolicy = "Retain"
+)
+
+// BlobStorageSpec defines the desired state of BlobStorage
+// +k8s:openapi-gen=true
+type BlobStorageSpec struct {
+	ResourceTypeSpec `json:",inline"`
+
+	// BucketName points CRO at a pre-existing, brownfield bucket instead of
+	// provisioning a new one. The bucket must already exist; CRO will only
+	// reconcile end-user credentials scoped to it.
+	BucketName string `json:"bucketName,omitempty"`
+
+	// ReclaimPolicy determines whether the bucket is deleted or retained
+	// when this BlobStorage CR is deleted. Defaults to Delete, except when
+	// BucketName is set, where it defaults to Retain since that bucket is
+	// brownfield and not owned by CRO.
+	ReclaimPolicy BlobStorageReclaimPolicy `json:"reclaimPolicy,omitempty"`
+
+	// Prefix, when set together with a shared `bucketPrefix` strategy config,
+	// scopes this CR to the key prefix `<bucketPrefix>/<namespace>-<name>/`
+	// inside a single pre-existing bucket shared by multiple BlobStorage CRs,
+	// instead of provisioning a bucket of its own.
+	Prefix string `json:"prefix,omitempty"`
+}
+
+// BlobStorageStatus defines the observed state of BlobStorage
+// +k8s:openapi-gen=true
+type BlobStorageStatus struct {
+	ResourceTypeStatus `json:",inline"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BlobStorage is the Schema for the blobstorages API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+type BlobStorage struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BlobStorageSpec   `json:"spec,omitempty"`
+	Status BlobStorageStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BlobStorageList contains a list of BlobStorage
+type BlobStorageList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BlobStorage `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BlobStorage{}, &BlobStorageList{})
+}
@@ -0,0 +1,43 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PostgresSpec defines the desired state of Postgres
+// +k8s:openapi-gen=true
+type PostgresSpec struct {
+	ResourceTypeSpec `json:",inline"`
+}
+
+// PostgresStatus defines the observed state of Postgres
+// +k8s:openapi-gen=true
+type PostgresStatus struct {
+	ResourceTypeStatus `json:",inline"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// Postgres is the Schema for the postgres API
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+type Postgres struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PostgresSpec   `json:"spec,omitempty"`
+	Status PostgresStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// PostgresList contains a list of Postgres
+type PostgresList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Postgres `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Postgres{}, &PostgresList{})
+}
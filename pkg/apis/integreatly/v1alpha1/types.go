@@ -0,0 +1,54 @@
+package v1alpha1
+
+import (
+	"github.com/integr8ly/cloud-resource-operator/pkg/apis/integreatly/v1alpha1/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StatusPhase is the current reconcile phase of a managed resource CR
+type StatusPhase string
+
+const (
+	PhaseNone          StatusPhase = ""
+	PhaseInProgress    StatusPhase = "in progress"
+	PhaseComplete      StatusPhase = "complete"
+	PhaseFailed        StatusPhase = "failed"
+	PhaseDeleteInProgress StatusPhase = "delete in progress"
+)
+
+// SecretRef is a reference to the secret a resource's connection details are
+// written to once provisioning completes
+type SecretRef struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+// ResourceTypeSpec is embedded by every managed resource CR (Postgres, Redis,
+// BlobStorage, SMTPCredentials) and selects the tier used to look up the
+// deployment strategy config for that resource
+type ResourceTypeSpec struct {
+	Type      string     `json:"type"`
+	Tier      string     `json:"tier"`
+	SecretRef *SecretRef `json:"secretRef"`
+}
+
+// ResourceTypeStatus is embedded by every managed resource CR's status block
+type ResourceTypeStatus struct {
+	Phase     StatusPhase         `json:"phase,omitempty"`
+	Message   types.StatusMessage `json:"message,omitempty"`
+	SecretRef *SecretRef          `json:"secretRef,omitempty"`
+	Strategy  string              `json:"strategy,omitempty"`
+	Provider  string              `json:"provider,omitempty"`
+	// JobStatuses records the outcome of the most recent run of each named periodic
+	// drift/sync job (see pkg/providers/scheduler) against this CR's cloud resource
+	JobStatuses []JobStatusCondition `json:"jobStatuses,omitempty"`
+}
+
+// JobStatusCondition records the outcome of the most recent run of a single named
+// periodic sync job registered against this CR, e.g. "s3-bucket-policy-sync"
+type JobStatusCondition struct {
+	Name        string      `json:"name"`
+	LastRunTime metav1.Time `json:"lastRunTime,omitempty"`
+	Success     bool        `json:"success"`
+	Message     string      `json:"message,omitempty"`
+}
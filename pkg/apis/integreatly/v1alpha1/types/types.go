@@ -0,0 +1,12 @@
+package types
+
+// StatusMessage is a human readable status for a managed resource, surfaced on
+// the owning CR's status block while a provider works towards Ready
+type StatusMessage string
+
+const (
+	StatusEmpty      StatusMessage = ""
+	StatusComplete   StatusMessage = "completed"
+	StatusInProgress StatusMessage = "in progress"
+	StatusError      StatusMessage = "error"
+)
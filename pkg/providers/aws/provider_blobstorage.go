@@ -19,6 +19,7 @@ import (
 	"github.com/integr8ly/cloud-resource-operator/pkg/resources"
 
 	"github.com/integr8ly/cloud-resource-operator/pkg/providers"
+	"github.com/integr8ly/cloud-resource-operator/pkg/providers/scheduler"
 
 	"github.com/aws/aws-sdk-go/aws"
 
@@ -32,6 +33,7 @@ import (
 
 const (
 	dataBucketName          = "bucketName"
+	dataBucketPrefix        = "bucketPrefix"
 	dataCredentialKeyID     = "credentialKeyID"
 	dataCredentialSecretKey = "credentialSecretKey"
 )
@@ -39,6 +41,7 @@ const (
 // BlobStorageDeploymentDetails Provider-specific details about the AWS S3 bucket created
 type BlobStorageDeploymentDetails struct {
 	BucketName          string
+	BucketPrefix        string
 	CredentialKeyID     string
 	CredentialSecretKey string
 }
@@ -46,6 +49,7 @@ type BlobStorageDeploymentDetails struct {
 func (d *BlobStorageDeploymentDetails) Data() map[string][]byte {
 	return map[string][]byte{
 		dataBucketName:          []byte(d.BucketName),
+		dataBucketPrefix:        []byte(d.BucketPrefix),
 		dataCredentialKeyID:     []byte(d.CredentialKeyID),
 		dataCredentialSecretKey: []byte(d.CredentialSecretKey),
 	}
@@ -55,18 +59,23 @@ var _ providers.BlobStorageProvider = (*BlobStorageProvider)(nil)
 
 // BlobStorageProvider BlobStorageProvider implementation for AWS S3
 type BlobStorageProvider struct {
-	Client            client.Client
-	Logger            *logrus.Entry
-	CredentialManager CredentialManager
-	ConfigManager     ConfigManager
+	Client                 client.Client
+	Logger                 *logrus.Entry
+	CredentialManager      CredentialManager
+	ConfigManager          ConfigManager
+	Scheduler              scheduler.JobRegistry
+	SchedulerConfigManager scheduler.ConfigManager
 }
 
 func NewAWSBlobStorageProvider(client client.Client, logger *logrus.Entry) *BlobStorageProvider {
+	l := logger.WithFields(logrus.Fields{"provider": "aws_s3"})
 	return &BlobStorageProvider{
-		Client:            client,
-		Logger:            logger.WithFields(logrus.Fields{"provider": "aws_s3"}),
-		CredentialManager: NewCredentialMinterCredentialManager(client),
-		ConfigManager:     NewDefaultConfigMapConfigManager(client),
+		Client:                 client,
+		Logger:                 l,
+		CredentialManager:      NewCredentialManager(client, l),
+		ConfigManager:          NewDefaultConfigMapConfigManager(client),
+		Scheduler:              scheduler.Default(l),
+		SchedulerConfigManager: scheduler.NewDefaultConfigMapConfigManager(client),
 	}
 }
 
@@ -92,18 +101,20 @@ func (p *BlobStorageProvider) CreateStorage(ctx context.Context, bs *v1alpha1.Bl
 
 	// info about the bucket to be created
 	p.Logger.Infof("getting aws s3 bucket config for blob storage instance %s", bs.Name)
-	bucketCreateCfg, stratCfg, err := p.getS3BucketConfig(ctx, bs)
+	bucketCreateCfg, bucketPrefix, stratCfg, err := p.getS3BucketConfig(ctx, bs)
 	if err != nil {
 		return nil, errorUtil.Wrapf(err, "failed to retrieve aws s3 bucket config for blob storage instance %s", bs.Name)
 	}
 	if bucketCreateCfg.Bucket == nil {
 		bucketCreateCfg.Bucket = aws.String(fmt.Sprintf("%s-%s", bs.Namespace, bs.Name))
 	}
+	resolvedPrefix := resolveBucketPrefix(bucketPrefix, bs)
 
-	// create the credentials to be used by the end-user, whoever created the blobstorage instance
+	// create the credentials to be used by the end-user, whoever created the blobstorage instance, scoped
+	// to the key prefix alone when this CR shares a bucket with other BlobStorage CRs
 	endUserCredsName := fmt.Sprintf("cloud-resources-aws-s3-%s-credentials", bs.Name)
 	p.Logger.Infof("creating end-user credentials with name %s for managing s3 bucket %s", endUserCredsName, *bucketCreateCfg.Bucket)
-	endUserCreds, _, err := p.CredentialManager.ReoncileBucketOwnerCredentials(ctx, endUserCredsName, bs.Namespace, *bucketCreateCfg.Bucket)
+	endUserCreds, _, err := p.CredentialManager.ReoncileBucketOwnerCredentials(ctx, endUserCredsName, bs.Namespace, *bucketCreateCfg.Bucket, resolvedPrefix)
 	if err != nil {
 		return nil, errorUtil.Wrapf(err, "failed to reconcile s3 end-user credentials for blob storage instance %s", bs.Name)
 	}
@@ -123,6 +134,26 @@ func (p *BlobStorageProvider) CreateStorage(ctx context.Context, bs *v1alpha1.Bl
 	}))
 	s3svc := s3.New(sess)
 
+	// pre-create the blobstorageinstance that will be returned if everything is successful
+	bsi := &providers.BlobStorageInstance{
+		DeploymentDetails: &BlobStorageDeploymentDetails{
+			BucketName:          *bucketCreateCfg.Bucket,
+			BucketPrefix:        resolvedPrefix,
+			CredentialKeyID:     endUserCreds.AccessKeyID,
+			CredentialSecretKey: endUserCreds.SecretAccessKey,
+		},
+	}
+
+	p.Logger.Infof("registering periodic sync jobs for blob storage instance %s", bs.Name)
+	if err := p.RegisterSyncJobs(ctx, p.Scheduler, p.SchedulerConfigManager, bs); err != nil {
+		return nil, errorUtil.Wrapf(err, "failed to register sync jobs for blob storage instance %s", bs.Name)
+	}
+
+	if resolvedPrefix != "" {
+		p.Logger.Infof("blob storage instance %s owns prefix %s in shared s3 bucket %s, skipping bucket creation", bs.Name, resolvedPrefix, *bucketCreateCfg.Bucket)
+		return bsi, nil
+	}
+
 	// the aws access key can sometimes still not be registered in aws on first try, so loop
 	p.Logger.Infof("listing existing aws s3 buckets")
 	var existingBuckets []*s3.Bucket
@@ -138,15 +169,6 @@ func (p *BlobStorageProvider) CreateStorage(ctx context.Context, bs *v1alpha1.Bl
 		return nil, errorUtil.Wrapf(err, "timed out waiting to list s3 buckets, searching for blob storage instance %s", bs.Name)
 	}
 
-	// pre-create the blobstorageinstance that will be returned if everything is successful
-	bsi := &providers.BlobStorageInstance{
-		DeploymentDetails: &BlobStorageDeploymentDetails{
-			BucketName:          *bucketCreateCfg.Bucket,
-			CredentialKeyID:     endUserCreds.AccessKeyID,
-			CredentialSecretKey: endUserCreds.SecretAccessKey,
-		},
-	}
-
 	// create bucket if it doesn't already exist, if it does exist then use the existing bucket
 	p.Logger.Infof("checking if aws s3 bucket %s already exists", *bucketCreateCfg.Bucket)
 	var foundBucket *s3.Bucket
@@ -160,6 +182,9 @@ func (p *BlobStorageProvider) CreateStorage(ctx context.Context, bs *v1alpha1.Bl
 		p.Logger.Infof("bucket %s already exists, using that", *foundBucket.Name)
 		return bsi, nil
 	}
+	if bs.Spec.BucketName != "" {
+		return nil, errorUtil.New(fmt.Sprintf("bucket %s does not exist, a pre-existing bucket is required when spec.bucketName is set for blob storage instance %s", *bucketCreateCfg.Bucket, bs.Name))
+	}
 	p.Logger.Infof("bucket %s not found, creating bucket", *bucketCreateCfg.Bucket)
 	_, err = s3svc.CreateBucket(bucketCreateCfg)
 	if err != nil {
@@ -174,46 +199,58 @@ func (p *BlobStorageProvider) DeleteStorage(ctx context.Context, bs *v1alpha1.Bl
 	p.Logger.Infof("deleting blob storage instance %s via aws s3", bs.Name)
 	// resolve bucket information for bucket created by provider
 	p.Logger.Infof("getting aws s3 bucket config for blob storage instance %s", bs.Name)
-	bucketCreateCfg, stratCfg, err := p.getS3BucketConfig(ctx, bs)
+	bucketCreateCfg, bucketPrefix, stratCfg, err := p.getS3BucketConfig(ctx, bs)
 	if err != nil {
 		return errorUtil.Wrapf(err, "failed to retrieve aws s3 bucket config for blob storage instance %s", bs.Name)
 	}
 	if bucketCreateCfg.Bucket == nil {
 		bucketCreateCfg.Bucket = aws.String(fmt.Sprintf("%s-%s", bs.Namespace, bs.Name))
 	}
-
-	// get provider aws creds so the bucket can be deleted
-	p.Logger.Infof("creating provider credentials for creating s3 buckets, in namespace %s", bs.Namespace)
-	providerCreds, err := p.CredentialManager.ReconcileProviderCredentials(ctx, bs.Namespace)
-	if err != nil {
-		return errorUtil.Wrapf(err, "failed to reconcile aws provider credentials for blob storage instance %s", bs.Name)
-	}
-	sess := session.Must(session.NewSession(&aws.Config{
-		Region:      aws.String(stratCfg.Region),
-		Credentials: credentials.NewStaticCredentials(providerCreds.AccessKeyID, providerCreds.SecretAccessKey, ""),
-	}))
-
-	// delete the bucket that was created by the provider
-	p.Logger.Infof("creating new aws sdk session in region %s", stratCfg.Region)
-	s3svc := s3.New(sess)
-
-	_, err = s3svc.DeleteBucket(&s3.DeleteBucketInput{
-		Bucket: bucketCreateCfg.Bucket,
-	})
-	s3err, isAWSErr := err.(awserr.Error)
-	if err != nil && !isAWSErr {
-		return errorUtil.Wrapf(err, "failed to delete s3 bucket %s", *bucketCreateCfg.Bucket)
-	}
-	if err != nil && isAWSErr {
-		if s3err.Code() != s3.ErrCodeNoSuchBucket {
-			return errorUtil.Wrapf(err, "failed to delete aws s3 bucket %s, aws error", *bucketCreateCfg.Bucket)
+	resolvedPrefix := resolveBucketPrefix(bucketPrefix, bs)
+
+	if effectiveReclaimPolicy(bs) == v1alpha1.BlobStorageReclaimPolicyRetain {
+		p.Logger.Infof("reclaim policy is %s, leaving s3 bucket %s in place for blob storage instance %s", v1alpha1.BlobStorageReclaimPolicyRetain, *bucketCreateCfg.Bucket, bs.Name)
+	} else {
+		// get provider aws creds so the bucket or prefix can be cleaned up
+		p.Logger.Infof("creating provider credentials for creating s3 buckets, in namespace %s", bs.Namespace)
+		providerCreds, err := p.CredentialManager.ReconcileProviderCredentials(ctx, bs.Namespace)
+		if err != nil {
+			return errorUtil.Wrapf(err, "failed to reconcile aws provider credentials for blob storage instance %s", bs.Name)
+		}
+		p.Logger.Infof("creating new aws sdk session in region %s", stratCfg.Region)
+		sess := session.Must(session.NewSession(&aws.Config{
+			Region:      aws.String(stratCfg.Region),
+			Credentials: credentials.NewStaticCredentials(providerCreds.AccessKeyID, providerCreds.SecretAccessKey, ""),
+		}))
+		s3svc := s3.New(sess)
+
+		if resolvedPrefix != "" {
+			// this CR doesn't own the bucket, only the objects under its prefix
+			p.Logger.Infof("deleting objects under prefix %s in shared s3 bucket %s", resolvedPrefix, *bucketCreateCfg.Bucket)
+			if err := deleteS3BucketPrefix(s3svc, *bucketCreateCfg.Bucket, resolvedPrefix); err != nil {
+				return errorUtil.Wrapf(err, "failed to delete objects under prefix %s in s3 bucket %s", resolvedPrefix, *bucketCreateCfg.Bucket)
+			}
+		} else {
+			// delete the bucket that was created by the provider
+			_, err = s3svc.DeleteBucket(&s3.DeleteBucketInput{
+				Bucket: bucketCreateCfg.Bucket,
+			})
+			s3err, isAWSErr := err.(awserr.Error)
+			if err != nil && !isAWSErr {
+				return errorUtil.Wrapf(err, "failed to delete s3 bucket %s", *bucketCreateCfg.Bucket)
+			}
+			if err != nil && isAWSErr {
+				if s3err.Code() != s3.ErrCodeNoSuchBucket {
+					return errorUtil.Wrapf(err, "failed to delete aws s3 bucket %s, aws error", *bucketCreateCfg.Bucket)
+				}
+			}
+			err = s3svc.WaitUntilBucketNotExists(&s3.HeadBucketInput{
+				Bucket: bucketCreateCfg.Bucket,
+			})
+			if err != nil {
+				return errorUtil.Wrapf(err, "failed to wait for s3 bucket deletion, %s", *bucketCreateCfg.Bucket)
+			}
 		}
-	}
-	err = s3svc.WaitUntilBucketNotExists(&s3.HeadBucketInput{
-		Bucket: bucketCreateCfg.Bucket,
-	})
-	if err != nil {
-		return errorUtil.Wrapf(err, "failed to wait for s3 bucket deletion, %s", *bucketCreateCfg.Bucket)
 	}
 
 	// remove the credentials request created by the provider
@@ -239,10 +276,83 @@ func (p *BlobStorageProvider) DeleteStorage(ctx context.Context, bs *v1alpha1.Bl
 	return nil
 }
 
-func (p *BlobStorageProvider) getS3BucketConfig(ctx context.Context, bs *v1alpha1.BlobStorage) (*s3.CreateBucketInput, *StrategyConfig, error) {
+// jobBucketTagSync is the name of the periodic job registered by RegisterSyncJobs
+const jobBucketTagSync = "s3-bucket-tag-sync"
+
+// RegisterSyncJobs registers a periodic job with registry that re-asserts bs's s3 bucket tags,
+// correcting drift introduced outside of a CreateStorage reconcile. It should be called once
+// per blob storage instance, alongside CreateStorage
+func (p *BlobStorageProvider) RegisterSyncJobs(ctx context.Context, registry scheduler.JobRegistry, cfgMgr scheduler.ConfigManager, bs *v1alpha1.BlobStorage) error {
+	schedule, err := cfgMgr.GetJobSchedule(ctx, jobBucketTagSync, "@every 30m")
+	if err != nil {
+		return errorUtil.Wrapf(err, "failed to resolve schedule for job %s", jobBucketTagSync)
+	}
+	if schedule == "" {
+		p.Logger.Infof("job %s is disabled for blob storage instance %s", jobBucketTagSync, bs.Name)
+		return nil
+	}
+	return registry.RegisterJob(scheduler.Job{
+		Name:     fmt.Sprintf("%s-%s-%s", jobBucketTagSync, bs.Namespace, bs.Name),
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			return p.syncBucketTags(ctx, bs)
+		},
+	})
+}
+
+// syncBucketTags re-applies bs's s3 bucket tags and records the outcome on bs's status
+func (p *BlobStorageProvider) syncBucketTags(ctx context.Context, bs *v1alpha1.BlobStorage) error {
+	bucketCreateCfg, bucketPrefix, stratCfg, err := p.getS3BucketConfig(ctx, bs)
+	if err != nil {
+		resources.SetJobStatusCondition(&bs.Status.ResourceTypeStatus, jobBucketTagSync, false, err.Error())
+		return errorUtil.Wrapf(err, "failed to retrieve aws s3 bucket config for blob storage instance %s", bs.Name)
+	}
+	if bucketCreateCfg.Bucket == nil {
+		bucketCreateCfg.Bucket = aws.String(fmt.Sprintf("%s-%s", bs.Namespace, bs.Name))
+	}
+	if resolveBucketPrefix(bucketPrefix, bs) != "" {
+		// this CR owns a prefix inside a shared bucket, not the bucket itself, so it has no
+		// tags of its own to sync
+		resources.SetJobStatusCondition(&bs.Status.ResourceTypeStatus, jobBucketTagSync, true, "")
+		return p.Client.Status().Update(ctx, bs)
+	}
+
+	providerCreds, err := p.CredentialManager.ReconcileProviderCredentials(ctx, bs.Namespace)
+	if err != nil {
+		resources.SetJobStatusCondition(&bs.Status.ResourceTypeStatus, jobBucketTagSync, false, err.Error())
+		return errorUtil.Wrapf(err, "failed to reconcile aws provider credentials for blob storage instance %s", bs.Name)
+	}
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String(stratCfg.Region),
+		Credentials: credentials.NewStaticCredentials(providerCreds.AccessKeyID, providerCreds.SecretAccessKey, ""),
+	}))
+	s3svc := s3.New(sess)
+
+	if _, err := s3svc.PutBucketTagging(&s3.PutBucketTaggingInput{
+		Bucket: bucketCreateCfg.Bucket,
+		Tagging: &s3.Tagging{
+			TagSet: []*s3.Tag{
+				{Key: aws.String("red-hat-managed"), Value: aws.String("true")},
+			},
+		},
+	}); err != nil {
+		resources.SetJobStatusCondition(&bs.Status.ResourceTypeStatus, jobBucketTagSync, false, err.Error())
+		return errorUtil.Wrapf(err, "failed to sync tags for s3 bucket %s", *bucketCreateCfg.Bucket)
+	}
+
+	resources.SetJobStatusCondition(&bs.Status.ResourceTypeStatus, jobBucketTagSync, true, "")
+	if err := p.Client.Status().Update(ctx, bs); err != nil {
+		return errorUtil.Wrapf(err, "failed to update status for blob storage instance %s", bs.Name)
+	}
+	return nil
+}
+
+// getS3BucketConfig returns the bucket creation input, the raw shared bucket-prefix
+// (before it's resolved to this CR's own prefix via resolveBucketPrefix) and the strategy config
+func (p *BlobStorageProvider) getS3BucketConfig(ctx context.Context, bs *v1alpha1.BlobStorage) (*s3.CreateBucketInput, string, *StrategyConfig, error) {
 	stratCfg, err := p.ConfigManager.ReadBlobStorageStrategy(ctx, bs.Spec.Tier)
 	if err != nil {
-		return nil, nil, errorUtil.Wrap(err, "failed to read aws strategy config")
+		return nil, "", nil, errorUtil.Wrap(err, "failed to read aws strategy config")
 	}
 	if stratCfg.Region == "" {
 		p.Logger.Debugf("region not set in deployment strategy configuration, using default region %s", DefaultRegion)
@@ -252,7 +362,69 @@ func (p *BlobStorageProvider) getS3BucketConfig(ctx context.Context, bs *v1alpha
 	// delete the s3 bucket created by the provider
 	s3cbi := &s3.CreateBucketInput{}
 	if err = json.Unmarshal(stratCfg.RawStrategy, s3cbi); err != nil {
-		return nil, nil, errorUtil.Wrap(err, "failed to unmarshal aws s3 configuration")
+		return nil, "", nil, errorUtil.Wrap(err, "failed to unmarshal aws s3 configuration")
+	}
+	if bs.Spec.BucketName != "" {
+		s3cbi.Bucket = aws.String(bs.Spec.BucketName)
+	}
+
+	// bucketPrefix marks the configured bucket as shared: CRs that resolve a
+	// non-empty prefix own only a key prefix inside it, not the bucket itself
+	rawCfg := &struct {
+		BucketPrefix string `json:"bucketPrefix"`
+	}{}
+	if err = json.Unmarshal(stratCfg.RawStrategy, rawCfg); err != nil {
+		return nil, "", nil, errorUtil.Wrap(err, "failed to unmarshal aws s3 configuration")
+	}
+	bucketPrefix := rawCfg.BucketPrefix
+	if bs.Spec.Prefix != "" {
+		bucketPrefix = bs.Spec.Prefix
+	}
+	return s3cbi, bucketPrefix, stratCfg, nil
+}
+
+// effectiveReclaimPolicy is bs.Spec.ReclaimPolicy, defaulted to Retain rather than Delete when
+// bs.Spec.BucketName is set: a brownfield bucket is owned by whoever created it, not CRO, so
+// deleting the CR must not delete it unless an operator explicitly opts back into Delete
+func effectiveReclaimPolicy(bs *v1alpha1.BlobStorage) v1alpha1.BlobStorageReclaimPolicy {
+	if bs.Spec.ReclaimPolicy == "" && bs.Spec.BucketName != "" {
+		return v1alpha1.BlobStorageReclaimPolicyRetain
+	}
+	return bs.Spec.ReclaimPolicy
+}
+
+// resolveBucketPrefix turns the shared root bucketPrefix into the key prefix this
+// blob storage instance owns inside the shared bucket, or "" if bucketPrefix is unset
+func resolveBucketPrefix(bucketPrefix string, bs *v1alpha1.BlobStorage) string {
+	if bucketPrefix == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s-%s", bucketPrefix, bs.Namespace, bs.Name)
+}
+
+// deleteS3BucketPrefix deletes every object under prefix in bucket, paging through
+// ListObjectsV2 and batching the removals via DeleteObjects
+func deleteS3BucketPrefix(s3svc *s3.S3, bucket, prefix string) error {
+	var deleteErr error
+	listErr := s3svc.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(fmt.Sprintf("%s/", prefix)),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		if len(page.Contents) == 0 {
+			return !lastPage
+		}
+		objects := make([]*s3.ObjectIdentifier, 0, len(page.Contents))
+		for _, obj := range page.Contents {
+			objects = append(objects, &s3.ObjectIdentifier{Key: obj.Key})
+		}
+		_, deleteErr = s3svc.DeleteObjects(&s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &s3.Delete{Objects: objects},
+		})
+		return deleteErr == nil && !lastPage
+	})
+	if listErr != nil {
+		return listErr
 	}
-	return s3cbi, stratCfg, nil
+	return deleteErr
 }
\ No newline at end of file
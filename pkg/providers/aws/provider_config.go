@@ -0,0 +1,98 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/integr8ly/cloud-resource-operator/pkg/providers"
+	"github.com/integr8ly/cloud-resource-operator/pkg/resources"
+	errorUtil "github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultRegion is used when a strategy config does not specify one
+	DefaultRegion = "eu-west-1"
+	// DefaultFinalizer is added to every BlobStorage CR this provider reconciles, so the
+	// bucket is torn down before the CR is allowed to be deleted
+	DefaultFinalizer = "finalizers.aws.cloud-resource-operator.integreatly.org"
+	// defaultConfigMapName holds the per-tier aws strategy configs, keyed by resource type
+	defaultConfigMapName = "cloud-resource-aws-strategies"
+)
+
+// StrategyConfig is the per-tier configuration read from the aws strategy
+// configmap for a given resource type (e.g. blobstorage)
+type StrategyConfig struct {
+	Region      string          `json:"region"`
+	RawStrategy json.RawMessage `json:"createStrategy"`
+}
+
+//go:generate moq -out provider_config_moq.go . ConfigManager
+type ConfigManager interface {
+	ReadBlobStorageStrategy(ctx context.Context, tier string) (*StrategyConfig, error)
+}
+
+var _ ConfigManager = (*ConfigMapConfigManager)(nil)
+
+// ConfigMapConfigManager reads aws strategy configs from a configmap, mirroring
+// azure.ConfigMapConfigManager and gcp.ConfigMapConfigManager
+type ConfigMapConfigManager struct {
+	client             client.Client
+	configMapName      string
+	configMapNamespace string
+}
+
+func NewDefaultConfigMapConfigManager(client client.Client) *ConfigMapConfigManager {
+	return NewConfigMapConfigManager(defaultConfigMapName, providers.DefaultConfigNamespace, client)
+}
+
+func NewConfigMapConfigManager(cm string, namespace string, client client.Client) *ConfigMapConfigManager {
+	if cm == "" {
+		cm = defaultConfigMapName
+	}
+	if namespace == "" {
+		namespace = providers.DefaultConfigNamespace
+	}
+	return &ConfigMapConfigManager{
+		client:             client,
+		configMapName:      cm,
+		configMapNamespace: namespace,
+	}
+}
+
+// ReadBlobStorageStrategy returns the strategy config for the blobstorage resource type and tier
+func (m *ConfigMapConfigManager) ReadBlobStorageStrategy(ctx context.Context, tier string) (*StrategyConfig, error) {
+	return m.readStrategy(ctx, "blobstorage", tier)
+}
+
+func (m *ConfigMapConfigManager) readStrategy(ctx context.Context, rt string, tier string) (*StrategyConfig, error) {
+	cm, err := resources.GetConfigMapOrDefault(ctx, m.client, types.NamespacedName{Name: m.configMapName, Namespace: m.configMapNamespace}, m.buildDefaultConfigMap())
+	if err != nil {
+		return nil, errorUtil.Wrapf(err, "failed to read aws strategy configmap %s in namespace %s", m.configMapName, m.configMapNamespace)
+	}
+	var tierStrategies map[string]*StrategyConfig
+	if err := json.Unmarshal([]byte(cm.Data[rt]), &tierStrategies); err != nil {
+		return nil, errorUtil.Wrapf(err, "failed to unmarshal aws strategy config for resource type %s", rt)
+	}
+	stratCfg, ok := tierStrategies[tier]
+	if !ok {
+		return nil, errorUtil.New(fmt.Sprintf("no strategy config found for tier %s, resource type %s", tier, rt))
+	}
+	return stratCfg, nil
+}
+
+func (m *ConfigMapConfigManager) buildDefaultConfigMap() *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: controllerruntime.ObjectMeta{
+			Name:      m.configMapName,
+			Namespace: m.configMapNamespace,
+		},
+		Data: map[string]string{
+			"blobstorage": "{\"development\": {\"region\": \"\", \"createStrategy\": {}}}",
+		},
+	}
+}
@@ -0,0 +1,332 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	v1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	errorUtil "github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultCredentialsSecretName is the secret CRO reads its cluster-admin aws credentials from
+// when running without cloud-credential-operator
+const defaultCredentialsSecretName = "aws-credentials"
+
+// credentialsRequestCRDName is the CRD cloud-credential-operator installs. Its presence is used
+// to decide which CredentialManager implementation NewAWSBlobStorageProvider wires up
+const credentialsRequestCRDName = "credentialsrequests.cloudcredential.openshift.io"
+
+// credentialsSourceEnvVar, when set, overrides CredentialManager discovery. Useful for clusters
+// where the credentialsrequests CRD is present but CRO should still use static secret
+// credentials, or vice versa
+const credentialsSourceEnvVar = "CRO_AWS_CREDENTIALS_SOURCE"
+
+// CredentialsSource selects where the aws provider gets its credentials from
+type CredentialsSource string
+
+const (
+	// CredentialsSourceCredentialsRequest requests scoped credentials from cloud-credential-operator.
+	// This is the default when the credentialsrequests CRD is registered on the cluster
+	CredentialsSourceCredentialsRequest CredentialsSource = "credentialsRequest"
+	// CredentialsSourceSecret reads a static cluster-admin aws-credentials secret and, where
+	// necessary, mints IAM users/policies/access keys itself. Used on clusters with no
+	// cloud-credential-operator installed, e.g. a boskos-provisioned kubetest2-kops target
+	CredentialsSourceSecret CredentialsSource = "secret"
+)
+
+// Credentials are the access key and secret used to authenticate against the AWS APIs
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+//go:generate moq -out provider_credentials_moq.go . CredentialManager
+type CredentialManager interface {
+	ReconcileProviderCredentials(ctx context.Context, namespace string) (*Credentials, error)
+	ReoncileBucketOwnerCredentials(ctx context.Context, name, namespace, bucketName, bucketPrefix string) (*Credentials, *v1.CredentialsRequest, error)
+}
+
+// NewCredentialManager picks the CredentialManager implementation CRO should use: the
+// cloud-credential-operator backed one when the credentialsrequests CRD is registered on the
+// cluster, falling back to reading a static aws-credentials secret otherwise.
+// CRO_AWS_CREDENTIALS_SOURCE overrides the discovery when set
+func NewCredentialManager(client client.Client, logger *logrus.Entry) CredentialManager {
+	switch CredentialsSource(os.Getenv(credentialsSourceEnvVar)) {
+	case CredentialsSourceSecret:
+		return NewStaticSecretCredentialManager(client)
+	case CredentialsSourceCredentialsRequest:
+		return NewCredentialMinterCredentialManager(client)
+	}
+	if isCredentialsRequestCRDInstalled(context.Background(), client) {
+		return NewCredentialMinterCredentialManager(client)
+	}
+	logger.Infof("%s CRD not found, falling back to static aws-credentials secret", credentialsRequestCRDName)
+	return NewStaticSecretCredentialManager(client)
+}
+
+// isCredentialsRequestCRDInstalled reports whether cloud-credential-operator's CRD is
+// registered on the cluster by asking the client to list it; an unregistered kind surfaces as
+// a no-match error against the client's REST mapper
+func isCredentialsRequestCRDInstalled(ctx context.Context, c client.Client) bool {
+	err := c.List(ctx, &v1.CredentialsRequestList{})
+	return !meta.IsNoMatchError(err)
+}
+
+var _ CredentialManager = (*CredentialMinterCredentialManager)(nil)
+
+// CredentialMinterCredentialManager requests scoped aws credentials from
+// cloud-credential-operator via CredentialsRequest CRs
+type CredentialMinterCredentialManager struct {
+	client client.Client
+}
+
+func NewCredentialMinterCredentialManager(client client.Client) *CredentialMinterCredentialManager {
+	return &CredentialMinterCredentialManager{
+		client: client,
+	}
+}
+
+// ReconcileProviderCredentials returns the credentials CRO uses to call the AWS APIs on behalf
+// of a CR in namespace, minted by cloud-credential-operator
+func (m *CredentialMinterCredentialManager) ReconcileProviderCredentials(ctx context.Context, namespace string) (*Credentials, error) {
+	creds, _, err := m.reconcileCredentialsRequest(ctx, "cloud-resources-aws-provider-creds", namespace, providerCredentialsStatementEntries())
+	return creds, err
+}
+
+// ReoncileBucketOwnerCredentials returns end-user credentials scoped to bucketName (and, when
+// bucketPrefix is set, to that key prefix alone), minted by cloud-credential-operator
+func (m *CredentialMinterCredentialManager) ReoncileBucketOwnerCredentials(ctx context.Context, name, namespace, bucketName, bucketPrefix string) (*Credentials, *v1.CredentialsRequest, error) {
+	return m.reconcileCredentialsRequest(ctx, name, namespace, bucketOwnerStatementEntries(bucketName, bucketPrefix))
+}
+
+func (m *CredentialMinterCredentialManager) reconcileCredentialsRequest(ctx context.Context, name, namespace string, statementEntries []statementEntry) (*Credentials, *v1.CredentialsRequest, error) {
+	credsSecretName := fmt.Sprintf("%s-secret", name)
+	providerSpec, err := json.Marshal(&awsProviderSpec{
+		TypeMeta:         metav1.TypeMeta{APIVersion: "cloudcredential.openshift.io/v1", Kind: "AWSProviderSpec"},
+		StatementEntries: statementEntries,
+	})
+	if err != nil {
+		return nil, nil, errorUtil.Wrapf(err, "failed to build aws provider spec for credentials request %s", name)
+	}
+
+	credReq := &v1.CredentialsRequest{}
+	nsName := types.NamespacedName{Name: name, Namespace: namespace}
+	if err := m.client.Get(ctx, nsName, credReq); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, nil, errorUtil.Wrapf(err, "failed to get credentials request %s in namespace %s", name, namespace)
+		}
+		credReq = &v1.CredentialsRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: v1.CredentialsRequestSpec{
+				SecretRef: corev1.ObjectReference{Name: credsSecretName, Namespace: namespace},
+				ProviderSpec: &runtime.RawExtension{Raw: providerSpec},
+			},
+		}
+		if err := m.client.Create(ctx, credReq); err != nil {
+			return nil, nil, errorUtil.Wrapf(err, "failed to create credentials request %s in namespace %s", name, namespace)
+		}
+	}
+
+	credsSecret := &corev1.Secret{}
+	if err := m.client.Get(ctx, types.NamespacedName{Name: credsSecretName, Namespace: namespace}, credsSecret); err != nil {
+		return nil, nil, errorUtil.Wrapf(err, "failed to get aws credentials secret %s in namespace %s, provisioned by cloud-credential-operator", credsSecretName, namespace)
+	}
+	return &Credentials{
+		AccessKeyID:     string(credsSecret.Data["aws_access_key_id"]),
+		SecretAccessKey: string(credsSecret.Data["aws_secret_access_key"]),
+	}, credReq, nil
+}
+
+var _ CredentialManager = (*StaticSecretCredentialManager)(nil)
+
+// StaticSecretCredentialManager reads a plain cluster-admin aws-credentials secret instead of
+// relying on cloud-credential-operator, for clusters where it isn't installed. Provider
+// credentials are the admin credentials as-is; bucket owner credentials are a dedicated IAM
+// user, policy and access key that this manager mints (and caches in a per-CR secret) the first
+// time it's asked for a bucket it hasn't seen before
+type StaticSecretCredentialManager struct {
+	client client.Client
+}
+
+func NewStaticSecretCredentialManager(client client.Client) *StaticSecretCredentialManager {
+	return &StaticSecretCredentialManager{
+		client: client,
+	}
+}
+
+// ReconcileProviderCredentials returns the cluster-admin aws-credentials secret as-is; it's
+// used both to drive the aws SDK directly and, in ReoncileBucketOwnerCredentials, to mint the
+// per-bucket IAM user via the IAM SDK
+func (m *StaticSecretCredentialManager) ReconcileProviderCredentials(ctx context.Context, namespace string) (*Credentials, error) {
+	credsSecret := &corev1.Secret{}
+	if err := m.client.Get(ctx, types.NamespacedName{Name: defaultCredentialsSecretName, Namespace: namespace}, credsSecret); err != nil {
+		return nil, errorUtil.Wrapf(err, "failed to get aws credentials secret %s in namespace %s", defaultCredentialsSecretName, namespace)
+	}
+	return &Credentials{
+		AccessKeyID:     string(credsSecret.Data["aws_access_key_id"]),
+		SecretAccessKey: string(credsSecret.Data["aws_secret_access_key"]),
+	}, nil
+}
+
+// ReoncileBucketOwnerCredentials mints (or reuses) an IAM user, policy and access key scoped to
+// bucketName/bucketPrefix using the admin credentials from the aws-credentials secret, since
+// there's no cloud-credential-operator here to do it. The access key is cached in a
+// <name>-iam-secret so it's only minted once per CR
+func (m *StaticSecretCredentialManager) ReoncileBucketOwnerCredentials(ctx context.Context, name, namespace, bucketName, bucketPrefix string) (*Credentials, *v1.CredentialsRequest, error) {
+	iamSecretName := fmt.Sprintf("%s-iam-secret", name)
+	iamSecret := &corev1.Secret{}
+	err := m.client.Get(ctx, types.NamespacedName{Name: iamSecretName, Namespace: namespace}, iamSecret)
+	if err == nil {
+		return &Credentials{
+			AccessKeyID:     string(iamSecret.Data["aws_access_key_id"]),
+			SecretAccessKey: string(iamSecret.Data["aws_secret_access_key"]),
+		}, nil, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, nil, errorUtil.Wrapf(err, "failed to get iam secret %s in namespace %s", iamSecretName, namespace)
+	}
+
+	adminCreds, err := m.ReconcileProviderCredentials(ctx, namespace)
+	if err != nil {
+		return nil, nil, errorUtil.Wrapf(err, "failed to get admin aws credentials to bootstrap iam user for bucket %s", bucketName)
+	}
+	sess := session.Must(session.NewSession(&aws.Config{
+		Credentials: credentials.NewStaticCredentials(adminCreds.AccessKeyID, adminCreds.SecretAccessKey, ""),
+	}))
+	iamSvc := iam.New(sess)
+
+	userName := fmt.Sprintf("cloud-resources-aws-s3-%s", name)
+	if _, err := iamSvc.CreateUser(&iam.CreateUserInput{UserName: aws.String(userName)}); err != nil {
+		if awsErr, ok := err.(awserr.Error); !ok || awsErr.Code() != iam.ErrCodeEntityAlreadyExistsException {
+			return nil, nil, errorUtil.Wrapf(err, "failed to create iam user %s for bucket %s", userName, bucketName)
+		}
+	}
+
+	policyDoc, err := json.Marshal(iamPolicyDocument(bucketOwnerStatementEntries(bucketName, bucketPrefix)))
+	if err != nil {
+		return nil, nil, errorUtil.Wrapf(err, "failed to build iam policy document for bucket %s", bucketName)
+	}
+	if _, err := iamSvc.PutUserPolicy(&iam.PutUserPolicyInput{
+		UserName:       aws.String(userName),
+		PolicyName:     aws.String(fmt.Sprintf("%s-policy", userName)),
+		PolicyDocument: aws.String(string(policyDoc)),
+	}); err != nil {
+		return nil, nil, errorUtil.Wrapf(err, "failed to attach iam policy to user %s for bucket %s", userName, bucketName)
+	}
+
+	accessKey, err := iamSvc.CreateAccessKey(&iam.CreateAccessKeyInput{UserName: aws.String(userName)})
+	if err != nil {
+		return nil, nil, errorUtil.Wrapf(err, "failed to create iam access key for user %s", userName)
+	}
+
+	creds := &Credentials{
+		AccessKeyID:     aws.StringValue(accessKey.AccessKey.AccessKeyId),
+		SecretAccessKey: aws.StringValue(accessKey.AccessKey.SecretAccessKey),
+	}
+	iamSecret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      iamSecretName,
+			Namespace: namespace,
+		},
+		StringData: map[string]string{
+			"aws_access_key_id":     creds.AccessKeyID,
+			"aws_secret_access_key": creds.SecretAccessKey,
+		},
+	}
+	if err := m.client.Create(ctx, iamSecret); err != nil {
+		return nil, nil, errorUtil.Wrapf(err, "failed to cache iam access key for user %s in secret %s", userName, iamSecretName)
+	}
+	return creds, nil, nil
+}
+
+// statementEntry mirrors cloud-credential-operator's AWSProviderSpec statement entry shape
+type statementEntry struct {
+	Effect    string                         `json:"effect"`
+	Action    []string                       `json:"action"`
+	Resource  string                         `json:"resource"`
+	Condition map[string]map[string][]string `json:"policyCondition,omitempty"`
+}
+
+// awsProviderSpec mirrors cloud-credential-operator's AWSProviderSpec, used as the
+// ProviderSpec payload of a CredentialsRequest
+type awsProviderSpec struct {
+	metav1.TypeMeta  `json:",inline"`
+	StatementEntries []statementEntry `json:"statementEntries"`
+}
+
+// iamPolicyDocument renders a set of statement entries as a standalone IAM policy document,
+// for use directly against the IAM API rather than via a CredentialsRequest
+type iamPolicyDocument []statementEntry
+
+func (d iamPolicyDocument) MarshalJSON() ([]byte, error) {
+	type statement struct {
+		Effect    string                         `json:"Effect"`
+		Action    []string                       `json:"Action"`
+		Resource  string                         `json:"Resource"`
+		Condition map[string]map[string][]string `json:"Condition,omitempty"`
+	}
+	statements := make([]statement, 0, len(d))
+	for _, e := range d {
+		statements = append(statements, statement{Effect: e.Effect, Action: e.Action, Resource: e.Resource, Condition: e.Condition})
+	}
+	return json.Marshal(&struct {
+		Version   string      `json:"Version"`
+		Statement []statement `json:"Statement"`
+	}{
+		Version:   "2012-10-17",
+		Statement: statements,
+	})
+}
+
+// providerCredentialsStatementEntries is the iam policy CRO's own provider credentials need to
+// list and create buckets, scoped loosely since the exact bucket name isn't known up front
+func providerCredentialsStatementEntries() []statementEntry {
+	return []statementEntry{
+		{
+			Effect:   "Allow",
+			Action:   []string{"s3:ListAllMyBuckets", "s3:CreateBucket", "s3:DeleteBucket"},
+			Resource: "arn:aws:s3:::*",
+		},
+	}
+}
+
+// bucketOwnerStatementEntries scopes end-user access to bucketName, and further to the key
+// prefix `bucketPrefix/*` when the CR shares bucketName with other BlobStorage CRs: s3:ListBucket
+// is gated by a Condition on s3:prefix, and object-level actions are scoped to that prefix alone
+func bucketOwnerStatementEntries(bucketName, bucketPrefix string) []statementEntry {
+	listBucket := statementEntry{
+		Effect:   "Allow",
+		Action:   []string{"s3:ListBucket"},
+		Resource: fmt.Sprintf("arn:aws:s3:::%s", bucketName),
+	}
+	objectResource := fmt.Sprintf("arn:aws:s3:::%s/*", bucketName)
+	if bucketPrefix != "" {
+		listBucket.Condition = map[string]map[string][]string{
+			"StringLike": {"s3:prefix": {fmt.Sprintf("%s/*", bucketPrefix)}},
+		}
+		objectResource = fmt.Sprintf("arn:aws:s3:::%s/%s/*", bucketName, bucketPrefix)
+	}
+	return []statementEntry{
+		listBucket,
+		{
+			Effect:   "Allow",
+			Action:   []string{"s3:GetObject", "s3:PutObject", "s3:DeleteObject"},
+			Resource: objectResource,
+		},
+	}
+}
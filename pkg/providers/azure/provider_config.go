@@ -0,0 +1,96 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/integr8ly/cloud-resource-operator/pkg/providers"
+	"github.com/integr8ly/cloud-resource-operator/pkg/resources"
+	errorUtil "github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultRegion is used when a strategy config does not specify one
+	DefaultRegion = "eastus"
+	// defaultConfigMapName holds the per-tier azure strategy configs, keyed by resource type
+	defaultConfigMapName = "cloud-resource-azure-strategies"
+)
+
+// StrategyConfig is the per-tier configuration read from the azure strategy
+// configmap for a given resource type (e.g. postgres)
+type StrategyConfig struct {
+	Region         string          `json:"region"`
+	ResourceGroup  string          `json:"resourceGroup"`
+	RawStrategy    json.RawMessage `json:"createStrategy"`
+}
+
+//go:generate moq -out provider_config_moq.go . ConfigManager
+type ConfigManager interface {
+	ReadPostgresStrategy(ctx context.Context, tier string) (*StrategyConfig, error)
+}
+
+var _ ConfigManager = (*ConfigMapConfigManager)(nil)
+
+// ConfigMapConfigManager reads azure strategy configs from a configmap, mirroring
+// aws.ConfigMapConfigManager
+type ConfigMapConfigManager struct {
+	client             client.Client
+	configMapName      string
+	configMapNamespace string
+}
+
+func NewDefaultConfigMapConfigManager(client client.Client) *ConfigMapConfigManager {
+	return NewConfigMapConfigManager(defaultConfigMapName, providers.DefaultConfigNamespace, client)
+}
+
+func NewConfigMapConfigManager(cm string, namespace string, client client.Client) *ConfigMapConfigManager {
+	if cm == "" {
+		cm = defaultConfigMapName
+	}
+	if namespace == "" {
+		namespace = providers.DefaultConfigNamespace
+	}
+	return &ConfigMapConfigManager{
+		client:             client,
+		configMapName:      cm,
+		configMapNamespace: namespace,
+	}
+}
+
+// ReadPostgresStrategy returns the strategy config for the postgres resource type and tier
+func (m *ConfigMapConfigManager) ReadPostgresStrategy(ctx context.Context, tier string) (*StrategyConfig, error) {
+	return m.readStrategy(ctx, "postgres", tier)
+}
+
+func (m *ConfigMapConfigManager) readStrategy(ctx context.Context, rt string, tier string) (*StrategyConfig, error) {
+	cm, err := resources.GetConfigMapOrDefault(ctx, m.client, types.NamespacedName{Name: m.configMapName, Namespace: m.configMapNamespace}, m.buildDefaultConfigMap())
+	if err != nil {
+		return nil, errorUtil.Wrapf(err, "failed to read azure strategy configmap %s in namespace %s", m.configMapName, m.configMapNamespace)
+	}
+	var tierStrategies map[string]*StrategyConfig
+	if err := json.Unmarshal([]byte(cm.Data[rt]), &tierStrategies); err != nil {
+		return nil, errorUtil.Wrapf(err, "failed to unmarshal azure strategy config for resource type %s", rt)
+	}
+	stratCfg, ok := tierStrategies[tier]
+	if !ok {
+		return nil, errorUtil.New(fmt.Sprintf("no strategy config found for tier %s, resource type %s", tier, rt))
+	}
+	return stratCfg, nil
+}
+
+func (m *ConfigMapConfigManager) buildDefaultConfigMap() *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: controllerruntime.ObjectMeta{
+			Name:      m.configMapName,
+			Namespace: m.configMapNamespace,
+		},
+		Data: map[string]string{
+			"postgres": "{\"development\": {\"region\": \"\", \"resourceGroup\": \"\", \"createStrategy\": {}}}",
+		},
+	}
+}
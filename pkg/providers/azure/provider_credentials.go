@@ -0,0 +1,56 @@
+package azure
+
+import (
+	"context"
+
+	errorUtil "github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultCredentialsSecretName is the secret CRO reads its azure service principal from
+const defaultCredentialsSecretName = "azure-creds"
+
+// Credentials are the service-principal details used to authenticate against
+// the Azure Resource Manager APIs
+type Credentials struct {
+	TenantID       string
+	ClientID       string
+	ClientSecret   string
+	SubscriptionID string
+}
+
+//go:generate moq -out provider_credentials_moq.go . CredentialManager
+type CredentialManager interface {
+	ReconcileProviderCredentials(ctx context.Context, namespace string) (*Credentials, error)
+}
+
+var _ CredentialManager = (*CredentialMinterCredentialManager)(nil)
+
+// CredentialMinterCredentialManager reads the azure service principal used by the
+// provider out of a plain secret, mirroring aws.CredentialMinterCredentialManager
+type CredentialMinterCredentialManager struct {
+	client client.Client
+}
+
+func NewCredentialMinterCredentialManager(client client.Client) *CredentialMinterCredentialManager {
+	return &CredentialMinterCredentialManager{
+		client: client,
+	}
+}
+
+// ReconcileProviderCredentials returns the service-principal credentials CRO uses to
+// call the Azure Resource Manager APIs on behalf of a CR in namespace
+func (m *CredentialMinterCredentialManager) ReconcileProviderCredentials(ctx context.Context, namespace string) (*Credentials, error) {
+	credsSecret := &v1.Secret{}
+	if err := m.client.Get(ctx, types.NamespacedName{Name: defaultCredentialsSecretName, Namespace: namespace}, credsSecret); err != nil {
+		return nil, errorUtil.Wrapf(err, "failed to get azure credentials secret %s in namespace %s", defaultCredentialsSecretName, namespace)
+	}
+	return &Credentials{
+		TenantID:       string(credsSecret.Data["tenantId"]),
+		ClientID:       string(credsSecret.Data["clientId"]),
+		ClientSecret:   string(credsSecret.Data["clientSecret"]),
+		SubscriptionID: string(credsSecret.Data["subscriptionId"]),
+	}, nil
+}
@@ -2,63 +2,371 @@ package azure
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/services/postgresql/mgmt/2017-12-01/postgresql"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
 	"github.com/integr8ly/cloud-resource-operator/pkg/apis/integreatly/v1alpha1"
 	"github.com/integr8ly/cloud-resource-operator/pkg/apis/integreatly/v1alpha1/types"
 	"github.com/integr8ly/cloud-resource-operator/pkg/providers"
+	"github.com/integr8ly/cloud-resource-operator/pkg/providers/scheduler"
 	"github.com/integr8ly/cloud-resource-operator/pkg/resources"
+	errorUtil "github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
-	v1 "k8s.io/api/core/v1"
-	types2 "k8s.io/apimachinery/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"time"
 )
 
-var _ providers.PostgresProvider = &PostgresProvider{}
+// DefaultFinalizer is added to every Postgres CR this provider reconciles, so the
+// server is torn down before the CR is allowed to be deleted
+const DefaultFinalizer = "finalizers.azure.cloud-resource-operator.integreatly.org"
+
+const (
+	defaultAdminUsername = "croadmin"
+	defaultDatabaseName   = "postgres"
+	defaultPostgresPort   = 5432
+)
+
+var _ providers.PostgresProvider = (*PostgresProvider)(nil)
 
 type PostgresProvider struct {
-	Logger *logrus.Entry
-	OpenShiftClient client.Client
+	Logger                 *logrus.Entry
+	OpenShiftClient        client.Client
+	CredentialManager      CredentialManager
+	ConfigManager          ConfigManager
+	Scheduler              scheduler.JobRegistry
+	SchedulerConfigManager scheduler.ConfigManager
 }
 
 func NewDefaultPostgresProvider(logger *logrus.Entry, client client.Client) *PostgresProvider {
+	l := logger.WithFields(logrus.Fields{"provider": "azure_postgres"})
 	return &PostgresProvider{
-		Logger:logger,
-		OpenShiftClient:client,
+		Logger:                 l,
+		OpenShiftClient:        client,
+		CredentialManager:      NewCredentialMinterCredentialManager(client),
+		ConfigManager:          NewDefaultConfigMapConfigManager(client),
+		Scheduler:              scheduler.Default(l),
+		SchedulerConfigManager: scheduler.NewDefaultConfigMapConfigManager(client),
 	}
 }
 
-func (p PostgresProvider) GetName() string {
+func (p *PostgresProvider) GetName() string {
 	return "Azure Postgres Provider"
 }
 
-func (p PostgresProvider) SupportsStrategy(s string) bool {
-	return s == "azure"
+func (p *PostgresProvider) SupportsStrategy(s string) bool {
+	return s == providers.AzureDeploymentStrategy
 }
 
-func (p PostgresProvider) GetReconcileTime(ps *v1alpha1.Postgres) time.Duration {
+func (p *PostgresProvider) GetReconcileTime(ps *v1alpha1.Postgres) time.Duration {
 	return resources.GetForcedReconcileTimeOrDefault(time.Second * 30)
 }
 
-func (p PostgresProvider) CreatePostgres(ctx context.Context, ps *v1alpha1.Postgres) (*providers.PostgresInstance, types.StatusMessage, error) {
-	p.Logger.Debug("creating postgres")
+// CreatePostgres provisions an Azure Database for PostgreSQL server for ps, polling the
+// ARM create operation until it finishes, and returns the connection details once ready
+func (p *PostgresProvider) CreatePostgres(ctx context.Context, ps *v1alpha1.Postgres) (*providers.PostgresInstance, types.StatusMessage, error) {
+	p.Logger.Infof("creating postgres instance %s via azure database for postgresql", ps.Name)
+
+	// handle provider-specific finalizer
+	if ps.GetDeletionTimestamp() == nil {
+		resources.AddFinalizer(&ps.ObjectMeta, DefaultFinalizer)
+		if err := p.OpenShiftClient.Update(ctx, ps); err != nil {
+			return nil, "failed to add finalizer", errorUtil.Wrapf(err, "failed to add finalizer to postgres instance %s", ps.Name)
+		}
+	}
+
+	stratCfg, serverCreateCfg, err := p.getPostgresServerConfig(ctx, ps)
+	if err != nil {
+		return nil, "failed to retrieve postgres strategy config", errorUtil.Wrapf(err, "failed to retrieve azure postgres config for instance %s", ps.Name)
+	}
+
+	creds, err := p.CredentialManager.ReconcileProviderCredentials(ctx, ps.Namespace)
+	if err != nil {
+		return nil, "failed to reconcile azure credentials", errorUtil.Wrapf(err, "failed to reconcile azure provider credentials for postgres instance %s", ps.Name)
+	}
+
+	serverName := fmt.Sprintf("%s-%s", ps.Namespace, ps.Name)
+
+	serversClient, err := p.newServersClient(creds)
+	if err != nil {
+		return nil, "failed to build azure client", errorUtil.Wrap(err, "failed to build postgresql servers client")
+	}
+
+	// if the server already exists, use its details rather than creating again
+	var adminPassword string
+	existing, err := serversClient.Get(ctx, stratCfg.ResourceGroup, serverName)
+	if err != nil && !isNotFoundErr(err) {
+		return nil, "failed to get postgres server", errorUtil.Wrapf(err, "failed to get azure postgres server %s", serverName)
+	}
+	if err != nil {
+		p.Logger.Infof("server %s not found, creating new azure postgres server", serverName)
+		adminPassword, err = resources.GeneratePassword()
+		if err != nil {
+			return nil, "failed to generate admin password", errorUtil.Wrap(err, "failed to generate postgres admin password")
+		}
+		if serverCreateCfg.Properties == nil {
+			serverCreateCfg.Properties = &postgresql.ServerPropertiesForDefaultCreate{}
+		}
+		if props, ok := serverCreateCfg.Properties.AsServerPropertiesForDefaultCreate(); ok {
+			props.AdministratorLogin = &[]string{defaultAdminUsername}[0]
+			props.AdministratorLoginPassword = &adminPassword
+			serverCreateCfg.Properties = props
+		}
+		serverCreateCfg.Location = &stratCfg.Region
+
+		future, err := serversClient.Create(ctx, stratCfg.ResourceGroup, serverName, *serverCreateCfg)
+		if err != nil {
+			return nil, "failed to create postgres server", errorUtil.Wrapf(err, "failed to create azure postgres server %s", serverName)
+		}
+		if err := future.WaitForCompletionRef(ctx, serversClient.Client); err != nil {
+			return nil, "failed to create postgres server", errorUtil.Wrapf(err, "failed to wait for azure postgres server %s to be created", serverName)
+		}
+		existing, err = future.Result(*serversClient)
+		if err != nil {
+			return nil, "failed to create postgres server", errorUtil.Wrapf(err, "failed to retrieve created azure postgres server %s", serverName)
+		}
+		if err := p.cacheAdminPassword(ctx, ps, adminPassword); err != nil {
+			return nil, "failed to cache admin password", errorUtil.Wrapf(err, "failed to cache admin password for postgres instance %s", ps.Name)
+		}
+	} else {
+		p.Logger.Infof("server %s already exists, reusing it", serverName)
+		adminPassword, err = p.getCachedAdminPassword(ctx, ps)
+		if err != nil {
+			return nil, "failed to retrieve cached admin password", errorUtil.Wrapf(err, "failed to retrieve cached admin password for postgres instance %s", ps.Name)
+		}
+	}
+
+	if err := p.reconcileFirewallRules(ctx, creds, stratCfg, serverName); err != nil {
+		return nil, "failed to reconcile firewall rules", errorUtil.Wrapf(err, "failed to reconcile firewall rules for azure postgres server %s", serverName)
+	}
+
+	p.Logger.Infof("registering periodic sync jobs for postgres instance %s", ps.Name)
+	if err := p.RegisterSyncJobs(ctx, p.Scheduler, p.SchedulerConfigManager, ps); err != nil {
+		return nil, "failed to register sync jobs", errorUtil.Wrapf(err, "failed to register sync jobs for postgres instance %s", ps.Name)
+	}
 
-	credsSecret := &v1.Secret{}
-	if err := p.OpenShiftClient.Get(ctx, types2.NamespacedName{Name: "azure-creds", Namespace: ps.Namespace}, credsSecret); err != nil {
-		return nil, "error", err
+	host := ""
+	if existing.FullyQualifiedDomainName != nil {
+		host = *existing.FullyQualifiedDomainName
 	}
-	p.Logger.Debugf("azure creds, key=%s, secret=%s", credsSecret.Data["key"], credsSecret.Data["secret"])
 
 	return &providers.PostgresInstance{
 		DeploymentDetails: &providers.PostgresDeploymentDetails{
-			Username: "test",
-			Password: "test",
-			Host:     "test",
-			Database: "test",
-			Port:     123,
+			Username: fmt.Sprintf("%s@%s", defaultAdminUsername, serverName),
+			Password: adminPassword,
+			Host:     host,
+			Database: defaultDatabaseName,
+			Port:     defaultPostgresPort,
+		},
+	}, types.StatusComplete, nil
+}
+
+// DeletePostgres tears down the azure postgres server and its firewall rules, and
+// removes the finalizer added by CreatePostgres
+func (p *PostgresProvider) DeletePostgres(ctx context.Context, ps *v1alpha1.Postgres) (types.StatusMessage, error) {
+	p.Logger.Infof("deleting postgres instance %s via azure database for postgresql", ps.Name)
+
+	stratCfg, _, err := p.getPostgresServerConfig(ctx, ps)
+	if err != nil {
+		return "failed to retrieve postgres strategy config", errorUtil.Wrapf(err, "failed to retrieve azure postgres config for instance %s", ps.Name)
+	}
+
+	creds, err := p.CredentialManager.ReconcileProviderCredentials(ctx, ps.Namespace)
+	if err != nil {
+		return "failed to reconcile azure credentials", errorUtil.Wrapf(err, "failed to reconcile azure provider credentials for postgres instance %s", ps.Name)
+	}
+
+	serversClient, err := p.newServersClient(creds)
+	if err != nil {
+		return "failed to build azure client", errorUtil.Wrap(err, "failed to build postgresql servers client")
+	}
+
+	serverName := fmt.Sprintf("%s-%s", ps.Namespace, ps.Name)
+	p.Logger.Infof("deleting azure postgres server %s", serverName)
+	future, err := serversClient.Delete(ctx, stratCfg.ResourceGroup, serverName)
+	if err != nil && !isNotFoundErr(err) {
+		return "failed to delete postgres server", errorUtil.Wrapf(err, "failed to delete azure postgres server %s", serverName)
+	}
+	if err == nil {
+		if err := future.WaitForCompletionRef(ctx, serversClient.Client); err != nil {
+			return "failed to delete postgres server", errorUtil.Wrapf(err, "failed to wait for azure postgres server %s to be deleted", serverName)
+		}
+	} else {
+		p.Logger.Infof("server %s not found, nothing to delete", serverName)
+	}
+
+	passwordSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: adminPasswordSecretName(ps), Namespace: ps.Namespace},
+	}
+	if err := p.OpenShiftClient.Delete(ctx, passwordSecret); err != nil && !apierrors.IsNotFound(err) {
+		return "failed to delete cached admin password", errorUtil.Wrapf(err, "failed to delete cached admin password secret %s", adminPasswordSecretName(ps))
+	}
+
+	p.Logger.Infof("removing finalizer %s from postgres instance %s", DefaultFinalizer, ps.Name)
+	resources.RemoveFinalizer(&ps.ObjectMeta, DefaultFinalizer)
+	if err := p.OpenShiftClient.Update(ctx, ps); err != nil {
+		return "failed to remove finalizer", errorUtil.Wrapf(err, "failed to update instance %s as part of finalizer reconcile", ps.Name)
+	}
+	p.Logger.Infof("deletion handler for postgres instance %s in namespace %s finished successfully", ps.Name, ps.Namespace)
+	return types.StatusComplete, nil
+}
+
+// jobFirewallRuleSync is the name of the periodic job registered by RegisterSyncJobs
+const jobFirewallRuleSync = "postgres-firewall-rule-sync"
+
+// RegisterSyncJobs registers a periodic job with registry that re-asserts ps's server firewall
+// rules, correcting drift introduced outside of a CreatePostgres reconcile. It should be called
+// once per postgres instance, alongside CreatePostgres, mirroring
+// aws.BlobStorageProvider.RegisterSyncJobs
+func (p *PostgresProvider) RegisterSyncJobs(ctx context.Context, registry scheduler.JobRegistry, cfgMgr scheduler.ConfigManager, ps *v1alpha1.Postgres) error {
+	schedule, err := cfgMgr.GetJobSchedule(ctx, jobFirewallRuleSync, "@every 30m")
+	if err != nil {
+		return errorUtil.Wrapf(err, "failed to resolve schedule for job %s", jobFirewallRuleSync)
+	}
+	if schedule == "" {
+		p.Logger.Infof("job %s is disabled for postgres instance %s", jobFirewallRuleSync, ps.Name)
+		return nil
+	}
+	return registry.RegisterJob(scheduler.Job{
+		Name:     fmt.Sprintf("%s-%s-%s", jobFirewallRuleSync, ps.Namespace, ps.Name),
+		Schedule: schedule,
+		Run: func(ctx context.Context) error {
+			return p.syncFirewallRules(ctx, ps)
+		},
+	})
+}
+
+// syncFirewallRules re-asserts ps's server firewall rules and records the outcome on ps's status
+func (p *PostgresProvider) syncFirewallRules(ctx context.Context, ps *v1alpha1.Postgres) error {
+	stratCfg, _, err := p.getPostgresServerConfig(ctx, ps)
+	if err != nil {
+		resources.SetJobStatusCondition(&ps.Status.ResourceTypeStatus, jobFirewallRuleSync, false, err.Error())
+		return errorUtil.Wrapf(err, "failed to retrieve azure postgres config for instance %s", ps.Name)
+	}
+
+	creds, err := p.CredentialManager.ReconcileProviderCredentials(ctx, ps.Namespace)
+	if err != nil {
+		resources.SetJobStatusCondition(&ps.Status.ResourceTypeStatus, jobFirewallRuleSync, false, err.Error())
+		return errorUtil.Wrapf(err, "failed to reconcile azure provider credentials for postgres instance %s", ps.Name)
+	}
+
+	serverName := fmt.Sprintf("%s-%s", ps.Namespace, ps.Name)
+	if err := p.reconcileFirewallRules(ctx, creds, stratCfg, serverName); err != nil {
+		resources.SetJobStatusCondition(&ps.Status.ResourceTypeStatus, jobFirewallRuleSync, false, err.Error())
+		return errorUtil.Wrapf(err, "failed to sync firewall rules for azure postgres server %s", serverName)
+	}
+
+	resources.SetJobStatusCondition(&ps.Status.ResourceTypeStatus, jobFirewallRuleSync, true, "")
+	if err := p.OpenShiftClient.Status().Update(ctx, ps); err != nil {
+		return errorUtil.Wrapf(err, "failed to update status for postgres instance %s", ps.Name)
+	}
+	return nil
+}
+
+func (p *PostgresProvider) getPostgresServerConfig(ctx context.Context, ps *v1alpha1.Postgres) (*StrategyConfig, *postgresql.ServerForCreate, error) {
+	stratCfg, err := p.ConfigManager.ReadPostgresStrategy(ctx, ps.Spec.Tier)
+	if err != nil {
+		return nil, nil, errorUtil.Wrap(err, "failed to read azure strategy config")
+	}
+	if stratCfg.Region == "" {
+		p.Logger.Debugf("region not set in deployment strategy configuration, using default region %s", DefaultRegion)
+		stratCfg.Region = DefaultRegion
+	}
+
+	serverCreateCfg := &postgresql.ServerForCreate{}
+	if err := json.Unmarshal(stratCfg.RawStrategy, serverCreateCfg); err != nil {
+		return nil, nil, errorUtil.Wrap(err, "failed to unmarshal azure postgres server configuration")
+	}
+	return stratCfg, serverCreateCfg, nil
+}
+
+func (p *PostgresProvider) reconcileFirewallRules(ctx context.Context, creds *Credentials, stratCfg *StrategyConfig, serverName string) error {
+	rulesClient, err := p.newFirewallRulesClient(creds)
+	if err != nil {
+		return errorUtil.Wrap(err, "failed to build postgresql firewall rules client")
+	}
+	ruleName := "allow-azure-services"
+	future, err := rulesClient.CreateOrUpdate(ctx, stratCfg.ResourceGroup, serverName, ruleName, postgresql.FirewallRule{
+		FirewallRuleProperties: &postgresql.FirewallRuleProperties{
+			StartIPAddress: &[]string{"0.0.0.0"}[0],
+			EndIPAddress:   &[]string{"0.0.0.0"}[0],
 		},
-	}, "completed", nil
+	})
+	if err != nil {
+		return errorUtil.Wrapf(err, "failed to create firewall rule %s for server %s", ruleName, serverName)
+	}
+	return future.WaitForCompletionRef(ctx, rulesClient.Client)
+}
+
+// adminPasswordSecretName is the secret cacheAdminPassword/getCachedAdminPassword cache ps's
+// admin password in, so CreatePostgres only generates (and sets on the server) a new one the
+// first time it's asked for a ps it hasn't seen before; every later reconcile, including once
+// the server already exists, reuses the cached password rather than generating one that's
+// never applied to the real server
+func adminPasswordSecretName(ps *v1alpha1.Postgres) string {
+	return fmt.Sprintf("cloud-resources-azure-postgres-%s-admin-credentials", ps.Name)
 }
 
-func (p PostgresProvider) DeletePostgres(ctx context.Context, ps *v1alpha1.Postgres) (types.StatusMessage, error) {
-	panic("implement me")
+func (p *PostgresProvider) cacheAdminPassword(ctx context.Context, ps *v1alpha1.Postgres, adminPassword string) error {
+	passwordSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      adminPasswordSecretName(ps),
+			Namespace: ps.Namespace,
+		},
+		StringData: map[string]string{
+			"password": adminPassword,
+		},
+	}
+	return p.OpenShiftClient.Create(ctx, passwordSecret)
+}
+
+func (p *PostgresProvider) getCachedAdminPassword(ctx context.Context, ps *v1alpha1.Postgres) (string, error) {
+	secretName := adminPasswordSecretName(ps)
+	passwordSecret := &corev1.Secret{}
+	if err := p.OpenShiftClient.Get(ctx, k8stypes.NamespacedName{Name: secretName, Namespace: ps.Namespace}, passwordSecret); err != nil {
+		return "", errorUtil.Wrapf(err, "failed to get cached admin password secret %s in namespace %s", secretName, ps.Namespace)
+	}
+	return string(passwordSecret.Data["password"]), nil
+}
+
+// isNotFoundErr reports whether err is the azure autorest error for a 404 response, the way
+// the aws s3 provider checks awserr.Error/s3.ErrCodeNoSuchBucket for a missing bucket
+func isNotFoundErr(err error) bool {
+	detailedErr, ok := err.(autorest.DetailedError)
+	if !ok {
+		return false
+	}
+	return detailedErr.StatusCode == http.StatusNotFound
+}
+
+func (p *PostgresProvider) newAuthorizer(creds *Credentials) (autorest.Authorizer, error) {
+	cfg := auth.NewClientCredentialsConfig(creds.ClientID, creds.ClientSecret, creds.TenantID)
+	return cfg.Authorizer()
+}
+
+func (p *PostgresProvider) newServersClient(creds *Credentials) (*postgresql.ServersClient, error) {
+	authorizer, err := p.newAuthorizer(creds)
+	if err != nil {
+		return nil, err
+	}
+	serversClient := postgresql.NewServersClient(creds.SubscriptionID)
+	serversClient.Authorizer = authorizer
+	return &serversClient, nil
+}
+
+func (p *PostgresProvider) newFirewallRulesClient(creds *Credentials) (*postgresql.FirewallRulesClient, error) {
+	authorizer, err := p.newAuthorizer(creds)
+	if err != nil {
+		return nil, err
+	}
+	rulesClient := postgresql.NewFirewallRulesClient(creds.SubscriptionID)
+	rulesClient.Authorizer = authorizer
+	return &rulesClient, nil
 }
@@ -18,8 +18,26 @@ import (
 const (
 	DefaultConfigNamespace       = "kube-system"
 	DefaultProviderConfigMapName = "cloud-resource-config"
+	// JobSchedulesConfigMapKey is the key inside the cloud-resource-config ConfigMap that holds
+	// scheduler.ConfigManager's per-job cron schedule overrides. It lives here, alongside the
+	// deployment-type strategy mappings, so both ConfigManagers that may bootstrap this
+	// ConfigMap build it from the one shared default in DefaultProviderConfigMapData
+	JobSchedulesConfigMapKey = "jobSchedules"
 )
 
+// DefaultProviderConfigMapData is the full default content of the cloud-resource-config
+// ConfigMap, spanning every ConfigManager that reads from it (this package's deployment-type
+// strategy mappings and scheduler.ConfigManager's job schedule overrides). Every one of those
+// ConfigManagers builds its default ConfigMap from this shared data, so whichever one bootstraps
+// the ConfigMap first doesn't silently create it missing the other's keys
+func DefaultProviderConfigMapData() map[string]string {
+	return map[string]string{
+		"managed":                "{\"blobstorage\":\"aws\", \"smtpcredentials\": \"aws\", \"redis\":\"aws\", \"postgres\":\"aws\"}",
+		"workshop":               "{\"blobstorage\":\"aws\", \"smtpcredentials\": \"aws\", \"redis\":\"openshift\", \"postgres\":\"openshift\"}",
+		JobSchedulesConfigMapKey: "{}",
+	}
+}
+
 type DeploymentStrategyMapping struct {
 	BlobStorage     string `json:"blobstorage"`
 	SMTPCredentials string `json:"smtpCredentials"`
@@ -73,9 +91,6 @@ func (m *ConfigMapConfigManager) buildDefaultConfigMap() *v1.ConfigMap {
 			Name:      m.providerConfigMapName,
 			Namespace: m.providerConfigMapNamespace,
 		},
-		Data: map[string]string{
-			"managed":  "{\"blobstorage\":\"aws\", \"smtpcredentials\": \"aws\", \"redis\":\"aws\", \"postgres\":\"aws\"}",
-			"workshop": "{\"blobstorage\":\"aws\", \"smtpcredentials\": \"aws\", \"redis\":\"openshift\", \"postgres\":\"openshift\"}",
-		},
+		Data: DefaultProviderConfigMapData(),
 	}
 }
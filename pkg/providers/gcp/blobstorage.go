@@ -0,0 +1,299 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"cloud.google.com/go/storage"
+	"github.com/integr8ly/cloud-resource-operator/pkg/apis/integreatly/v1alpha1"
+	"github.com/integr8ly/cloud-resource-operator/pkg/providers"
+	"github.com/integr8ly/cloud-resource-operator/pkg/resources"
+	errorUtil "github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/option"
+	gcpstorage "google.golang.org/api/storage/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultFinalizer is added to every BlobStorage CR this provider reconciles, so the
+	// bucket is torn down before the CR is allowed to be deleted
+	DefaultFinalizer = "finalizers.gcp.cloud-resource-operator.integreatly.org"
+
+	dataBucketName          = "bucketName"
+	dataCredentialKeyID     = "credentialKeyID"
+	dataCredentialSecretKey = "credentialSecretKey"
+
+	hmacKeySecretAccessID = "accessId"
+	hmacKeySecretSecret   = "secret"
+)
+
+// bucketCreateStrategy is unmarshaled from StrategyConfig.RawStrategy and describes
+// how a new bucket should be configured
+type bucketCreateStrategy struct {
+	Location                 string `json:"location"`
+	StorageClass              string `json:"storageClass"`
+	VersioningEnabled         bool   `json:"versioning"`
+	UniformBucketLevelAccess  bool   `json:"uniformBucketLevelAccess"`
+}
+
+// BlobStorageDeploymentDetails Provider-specific details about the GCS bucket created
+type BlobStorageDeploymentDetails struct {
+	BucketName          string
+	CredentialKeyID     string
+	CredentialSecretKey string
+}
+
+func (d *BlobStorageDeploymentDetails) Data() map[string][]byte {
+	return map[string][]byte{
+		dataBucketName:          []byte(d.BucketName),
+		dataCredentialKeyID:     []byte(d.CredentialKeyID),
+		dataCredentialSecretKey: []byte(d.CredentialSecretKey),
+	}
+}
+
+var _ providers.BlobStorageProvider = (*BlobStorageProvider)(nil)
+
+// BlobStorageProvider BlobStorageProvider implementation for Google Cloud Storage
+type BlobStorageProvider struct {
+	Client            client.Client
+	Logger            *logrus.Entry
+	CredentialManager CredentialManager
+	ConfigManager     ConfigManager
+}
+
+func NewGCPBlobStorageProvider(client client.Client, logger *logrus.Entry) *BlobStorageProvider {
+	return &BlobStorageProvider{
+		Client:            client,
+		Logger:            logger.WithFields(logrus.Fields{"provider": "gcp_storage"}),
+		CredentialManager: NewCredentialMinterCredentialManager(client),
+		ConfigManager:     NewDefaultConfigMapConfigManager(client),
+	}
+}
+
+func (p *BlobStorageProvider) GetName() string {
+	return providers.GCPDeploymentStrategy
+}
+
+func (p *BlobStorageProvider) SupportsStrategy(d string) bool {
+	return d == providers.GCPDeploymentStrategy
+}
+
+// CreateStorage reconciles a GCS bucket from strategy config and HMAC credentials
+// end-users can use to interact with it
+func (p *BlobStorageProvider) CreateStorage(ctx context.Context, bs *v1alpha1.BlobStorage) (*providers.BlobStorageInstance, error) {
+	p.Logger.Infof("creating blob storage instance %s via gcp storage", bs.Name)
+	// handle provider-specific finalizer
+	p.Logger.Infof("adding finalizer to blob storage instance %s", bs.Name)
+	if bs.GetDeletionTimestamp() == nil {
+		resources.AddFinalizer(&bs.ObjectMeta, DefaultFinalizer)
+		if err := p.Client.Update(ctx, bs); err != nil {
+			return nil, errorUtil.Wrapf(err, "failed to add finalizer to blob storage instance %s", bs.Name)
+		}
+	}
+
+	p.Logger.Infof("getting gcp storage bucket config for blob storage instance %s", bs.Name)
+	bucketStrategy, stratCfg, err := p.getBucketConfig(ctx, bs)
+	if err != nil {
+		return nil, errorUtil.Wrapf(err, "failed to retrieve gcp storage bucket config for blob storage instance %s", bs.Name)
+	}
+	bucketName := fmt.Sprintf("%s-%s", bs.Namespace, bs.Name)
+
+	creds, err := p.CredentialManager.ReconcileProviderCredentials(ctx, bs.Namespace)
+	if err != nil {
+		return nil, errorUtil.Wrapf(err, "failed to reconcile gcp provider credentials for blob storage instance %s", bs.Name)
+	}
+
+	gcsClient, err := storage.NewClient(ctx, option.WithCredentialsJSON(creds.ServiceAccountJSON))
+	if err != nil {
+		return nil, errorUtil.Wrapf(err, "failed to build gcp storage client for blob storage instance %s", bs.Name)
+	}
+	defer gcsClient.Close()
+
+	bucket := gcsClient.Bucket(bucketName)
+	p.Logger.Infof("checking if gcp storage bucket %s already exists", bucketName)
+	if _, err := bucket.Attrs(ctx); err != nil {
+		if err != storage.ErrBucketNotExist {
+			return nil, errorUtil.Wrapf(err, "failed to check if gcp storage bucket %s exists", bucketName)
+		}
+		p.Logger.Infof("bucket %s not found, creating bucket", bucketName)
+		if err := bucket.Create(ctx, stratCfg.ProjectID, &storage.BucketAttrs{
+			Location:                 bucketStrategy.Location,
+			StorageClass:             bucketStrategy.StorageClass,
+			VersioningEnabled:        bucketStrategy.VersioningEnabled,
+			UniformBucketLevelAccess: storage.UniformBucketLevelAccess{Enabled: bucketStrategy.UniformBucketLevelAccess},
+		}); err != nil {
+			return nil, errorUtil.Wrapf(err, "failed to create gcp storage bucket %s, for blob storage instance %s", bucketName, bs.Name)
+		}
+	} else {
+		p.Logger.Infof("bucket %s already exists, using that", bucketName)
+	}
+
+	p.Logger.Infof("reconciling hmac key for end-user access to bucket %s", bucketName)
+	hmacKeyID, hmacSecret, err := p.reconcileHMACKey(ctx, creds, stratCfg.ProjectID, bs)
+	if err != nil {
+		return nil, errorUtil.Wrapf(err, "failed to reconcile hmac key for blob storage instance %s", bs.Name)
+	}
+
+	p.Logger.Infof("creation handler for blob storage instance %s in namespace %s finished successfully", bs.Name, bs.Namespace)
+	return &providers.BlobStorageInstance{
+		DeploymentDetails: &BlobStorageDeploymentDetails{
+			BucketName:          bucketName,
+			CredentialKeyID:     hmacKeyID,
+			CredentialSecretKey: hmacSecret,
+		},
+	}, nil
+}
+
+// DeleteStorage deletes the gcp storage bucket and hmac key used to interact with it,
+// mirroring the aws s3 lifecycle
+func (p *BlobStorageProvider) DeleteStorage(ctx context.Context, bs *v1alpha1.BlobStorage) error {
+	p.Logger.Infof("deleting blob storage instance %s via gcp storage", bs.Name)
+	_, stratCfg, err := p.getBucketConfig(ctx, bs)
+	if err != nil {
+		return errorUtil.Wrapf(err, "failed to retrieve gcp storage bucket config for blob storage instance %s", bs.Name)
+	}
+	bucketName := fmt.Sprintf("%s-%s", bs.Namespace, bs.Name)
+
+	creds, err := p.CredentialManager.ReconcileProviderCredentials(ctx, bs.Namespace)
+	if err != nil {
+		return errorUtil.Wrapf(err, "failed to reconcile gcp provider credentials for blob storage instance %s", bs.Name)
+	}
+
+	gcsClient, err := storage.NewClient(ctx, option.WithCredentialsJSON(creds.ServiceAccountJSON))
+	if err != nil {
+		return errorUtil.Wrapf(err, "failed to build gcp storage client for blob storage instance %s", bs.Name)
+	}
+	defer gcsClient.Close()
+
+	p.Logger.Infof("deleting gcp storage bucket %s", bucketName)
+	if err := gcsClient.Bucket(bucketName).Delete(ctx); err != nil && err != storage.ErrBucketNotExist {
+		return errorUtil.Wrapf(err, "failed to delete gcp storage bucket %s", bucketName)
+	}
+
+	p.Logger.Infof("deleting hmac key for blob storage instance %s", bs.Name)
+	if err := p.deleteHMACKey(ctx, creds, stratCfg.ProjectID, bs); err != nil {
+		return errorUtil.Wrapf(err, "failed to delete hmac key for blob storage instance %s", bs.Name)
+	}
+	hmacKeySecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: hmacKeySecretName(bs), Namespace: bs.Namespace},
+	}
+	if err := p.Client.Delete(ctx, hmacKeySecret); err != nil && !apierrors.IsNotFound(err) {
+		return errorUtil.Wrapf(err, "failed to delete cached hmac key secret %s", hmacKeySecretName(bs))
+	}
+
+	p.Logger.Infof("deleting finalizer %s from blob storage instance %s in namespace %s", DefaultFinalizer, bs.Name, bs.Namespace)
+	resources.RemoveFinalizer(&bs.ObjectMeta, DefaultFinalizer)
+	if err := p.Client.Update(ctx, bs); err != nil {
+		return errorUtil.Wrapf(err, "failed to update instance %s as part of finalizer reconcile", bs.Name)
+	}
+	p.Logger.Infof("deletion handler for blob storage instance %s in namespace %s finished successfully", bs.Name, bs.Namespace)
+	return nil
+}
+
+func (p *BlobStorageProvider) getBucketConfig(ctx context.Context, bs *v1alpha1.BlobStorage) (*bucketCreateStrategy, *StrategyConfig, error) {
+	stratCfg, err := p.ConfigManager.ReadBlobStorageStrategy(ctx, bs.Spec.Tier)
+	if err != nil {
+		return nil, nil, errorUtil.Wrap(err, "failed to read gcp strategy config")
+	}
+	bucketStrategy := &bucketCreateStrategy{}
+	if err := json.Unmarshal(stratCfg.RawStrategy, bucketStrategy); err != nil {
+		return nil, nil, errorUtil.Wrap(err, "failed to unmarshal gcp storage bucket configuration")
+	}
+	return bucketStrategy, stratCfg, nil
+}
+
+// hmacKeySecretName is the secret reconcileHMACKey caches a minted hmac key's access id and
+// secret in, so it's only minted once per blob storage instance; a service account is capped
+// at 10 active hmac keys, so re-minting on every reconcile would eventually fail CreateStorage
+func hmacKeySecretName(bs *v1alpha1.BlobStorage) string {
+	return fmt.Sprintf("cloud-resources-gcp-storage-%s-hmac-secret", bs.Name)
+}
+
+// reconcileHMACKey returns the cached HMAC key for bs's blob storage instance, minting (and
+// caching in a hmacKeySecretName secret) a new one for the service account backing creds the
+// first time it's asked for a bs it hasn't seen before, so it can hand out end-user credentials
+// the way aws hands out a scoped IAM access key
+func (p *BlobStorageProvider) reconcileHMACKey(ctx context.Context, creds *Credentials, projectID string, bs *v1alpha1.BlobStorage) (string, string, error) {
+	secretName := hmacKeySecretName(bs)
+	cached := &corev1.Secret{}
+	err := p.Client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: bs.Namespace}, cached)
+	if err == nil {
+		return string(cached.Data[hmacKeySecretAccessID]), string(cached.Data[hmacKeySecretSecret]), nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return "", "", errorUtil.Wrapf(err, "failed to get cached hmac key secret %s in namespace %s", secretName, bs.Namespace)
+	}
+
+	svc, err := gcpstorage.NewService(ctx, option.WithCredentialsJSON(creds.ServiceAccountJSON))
+	if err != nil {
+		return "", "", errorUtil.Wrap(err, "failed to build gcp storage json client")
+	}
+	serviceAccountEmail, err := serviceAccountEmailFromJSON(creds.ServiceAccountJSON)
+	if err != nil {
+		return "", "", errorUtil.Wrap(err, "failed to parse service account email from credentials")
+	}
+	key, err := svc.Projects.HmacKeys.Create(projectID, serviceAccountEmail).Context(ctx).Do()
+	if err != nil {
+		return "", "", errorUtil.Wrapf(err, "failed to create hmac key for blob storage instance %s", bs.Name)
+	}
+
+	cacheSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: bs.Namespace},
+		StringData: map[string]string{
+			hmacKeySecretAccessID: key.AccessId,
+			hmacKeySecretSecret:   key.Secret,
+		},
+	}
+	if err := p.Client.Create(ctx, cacheSecret); err != nil {
+		return "", "", errorUtil.Wrapf(err, "failed to cache hmac key secret %s for blob storage instance %s", secretName, bs.Name)
+	}
+	return key.AccessId, key.Secret, nil
+}
+
+// deleteHMACKey deactivates and deletes only the hmac key cached for bs (looked up via
+// hmacKeySecretName), not every key on the shared service account: every GCS-backed BlobStorage
+// CR in the cluster authenticates as the same gcp-creds service account, so deleting any key
+// other than bs's own would revoke access for every other BlobStorage CR too
+func (p *BlobStorageProvider) deleteHMACKey(ctx context.Context, creds *Credentials, projectID string, bs *v1alpha1.BlobStorage) error {
+	secretName := hmacKeySecretName(bs)
+	cached := &corev1.Secret{}
+	err := p.Client.Get(ctx, types.NamespacedName{Name: secretName, Namespace: bs.Namespace}, cached)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errorUtil.Wrapf(err, "failed to get cached hmac key secret %s in namespace %s", secretName, bs.Namespace)
+	}
+	accessID := string(cached.Data[hmacKeySecretAccessID])
+	if accessID == "" {
+		return nil
+	}
+
+	svc, err := gcpstorage.NewService(ctx, option.WithCredentialsJSON(creds.ServiceAccountJSON))
+	if err != nil {
+		return errorUtil.Wrap(err, "failed to build gcp storage json client")
+	}
+	if _, err := svc.Projects.HmacKeys.Update(projectID, accessID, &gcpstorage.HmacKeyMetadata{State: "INACTIVE"}).Context(ctx).Do(); err != nil {
+		return errorUtil.Wrapf(err, "failed to deactivate hmac key %s", accessID)
+	}
+	if err := svc.Projects.HmacKeys.Delete(projectID, accessID).Context(ctx).Do(); err != nil {
+		return errorUtil.Wrapf(err, "failed to delete hmac key %s", accessID)
+	}
+	return nil
+}
+
+func serviceAccountEmailFromJSON(raw []byte) (string, error) {
+	sa := &struct {
+		ClientEmail string `json:"client_email"`
+	}{}
+	if err := json.Unmarshal(raw, sa); err != nil {
+		return "", err
+	}
+	return sa.ClientEmail, nil
+}
@@ -0,0 +1,50 @@
+package gcp
+
+import (
+	"context"
+
+	errorUtil "github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultCredentialsSecretName is the secret CRO reads the gcp service account key from
+const defaultCredentialsSecretName = "gcp-creds"
+
+// Credentials holds the raw JSON service account key used to authenticate against
+// the Google Cloud Storage APIs
+type Credentials struct {
+	ServiceAccountJSON []byte
+}
+
+//go:generate moq -out provider_credentials_moq.go . CredentialManager
+type CredentialManager interface {
+	ReconcileProviderCredentials(ctx context.Context, namespace string) (*Credentials, error)
+}
+
+var _ CredentialManager = (*CredentialMinterCredentialManager)(nil)
+
+// CredentialMinterCredentialManager reads the gcp service account key used by the
+// provider out of a plain secret, mirroring aws.CredentialMinterCredentialManager
+type CredentialMinterCredentialManager struct {
+	client client.Client
+}
+
+func NewCredentialMinterCredentialManager(client client.Client) *CredentialMinterCredentialManager {
+	return &CredentialMinterCredentialManager{
+		client: client,
+	}
+}
+
+// ReconcileProviderCredentials returns the gcp service account credentials CRO uses to
+// call the Google Cloud Storage APIs on behalf of a CR in namespace
+func (m *CredentialMinterCredentialManager) ReconcileProviderCredentials(ctx context.Context, namespace string) (*Credentials, error) {
+	credsSecret := &v1.Secret{}
+	if err := m.client.Get(ctx, types.NamespacedName{Name: defaultCredentialsSecretName, Namespace: namespace}, credsSecret); err != nil {
+		return nil, errorUtil.Wrapf(err, "failed to get gcp credentials secret %s in namespace %s", defaultCredentialsSecretName, namespace)
+	}
+	return &Credentials{
+		ServiceAccountJSON: credsSecret.Data["serviceAccountJson"],
+	}, nil
+}
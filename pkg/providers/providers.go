@@ -0,0 +1,70 @@
+package providers
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/integr8ly/cloud-resource-operator/pkg/apis/integreatly/v1alpha1"
+	"github.com/integr8ly/cloud-resource-operator/pkg/apis/integreatly/v1alpha1/types"
+)
+
+const (
+	AWSDeploymentStrategy   = "aws"
+	AzureDeploymentStrategy = "azure"
+	GCPDeploymentStrategy   = "gcp"
+)
+
+// DeploymentDetails is implemented by each provider's resource-specific deployment
+// details struct, and is used to flatten the provisioned resource's connection
+// information into the secret referenced by the owning CR
+type DeploymentDetails interface {
+	Data() map[string][]byte
+}
+
+// BlobStorageInstance wraps the provider-specific details of a reconciled bucket
+type BlobStorageInstance struct {
+	DeploymentDetails DeploymentDetails
+}
+
+//go:generate moq -out blobstorage_moq.go . BlobStorageProvider
+type BlobStorageProvider interface {
+	GetName() string
+	SupportsStrategy(s string) bool
+	CreateStorage(ctx context.Context, bs *v1alpha1.BlobStorage) (*BlobStorageInstance, error)
+	DeleteStorage(ctx context.Context, bs *v1alpha1.BlobStorage) error
+}
+
+// PostgresDeploymentDetails is the common shape of the connection details a
+// PostgresProvider exposes, regardless of which cloud provisioned the server
+type PostgresDeploymentDetails struct {
+	Username string
+	Password string
+	Host     string
+	Database string
+	Port     int
+}
+
+func (d *PostgresDeploymentDetails) Data() map[string][]byte {
+	return map[string][]byte{
+		"username": []byte(d.Username),
+		"password": []byte(d.Password),
+		"host":     []byte(d.Host),
+		"database": []byte(d.Database),
+		"port":     []byte(strconv.Itoa(d.Port)),
+	}
+}
+
+// PostgresInstance wraps the provider-specific details of a reconciled postgres server
+type PostgresInstance struct {
+	DeploymentDetails *PostgresDeploymentDetails
+}
+
+//go:generate moq -out postgres_moq.go . PostgresProvider
+type PostgresProvider interface {
+	GetName() string
+	SupportsStrategy(s string) bool
+	GetReconcileTime(ps *v1alpha1.Postgres) time.Duration
+	CreatePostgres(ctx context.Context, ps *v1alpha1.Postgres) (*PostgresInstance, types.StatusMessage, error)
+	DeletePostgres(ctx context.Context, ps *v1alpha1.Postgres) (types.StatusMessage, error)
+}
@@ -0,0 +1,80 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/integr8ly/cloud-resource-operator/pkg/providers"
+	"github.com/integr8ly/cloud-resource-operator/pkg/resources"
+	errorUtil "github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	controllerruntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+//go:generate moq -out provider_config_moq.go . ConfigManager
+// ConfigManager resolves the cron schedule a named job should run on, so operators can tune
+// or disable individual jobs from the cloud-resource-config ConfigMap without a code change
+type ConfigManager interface {
+	GetJobSchedule(ctx context.Context, jobName string, defaultSchedule string) (string, error)
+}
+
+var _ ConfigManager = (*ConfigMapConfigManager)(nil)
+
+// ConfigMapConfigManager reads job schedule overrides from the cloud-resource-config ConfigMap
+type ConfigMapConfigManager struct {
+	client             client.Client
+	configMapName      string
+	configMapNamespace string
+}
+
+func NewDefaultConfigMapConfigManager(client client.Client) *ConfigMapConfigManager {
+	return NewConfigMapConfigManager(providers.DefaultProviderConfigMapName, providers.DefaultConfigNamespace, client)
+}
+
+func NewConfigMapConfigManager(cm string, namespace string, client client.Client) *ConfigMapConfigManager {
+	if cm == "" {
+		cm = providers.DefaultProviderConfigMapName
+	}
+	if namespace == "" {
+		namespace = providers.DefaultConfigNamespace
+	}
+	return &ConfigMapConfigManager{
+		client:             client,
+		configMapName:      cm,
+		configMapNamespace: namespace,
+	}
+}
+
+// GetJobSchedule returns the cron schedule configured for jobName, or defaultSchedule if the
+// cloud-resource-config ConfigMap has no override for it. An override of "" disables the job;
+// callers should skip registering a job whose resolved schedule is empty
+func (m *ConfigMapConfigManager) GetJobSchedule(ctx context.Context, jobName string, defaultSchedule string) (string, error) {
+	cm, err := resources.GetConfigMapOrDefault(ctx, m.client, types.NamespacedName{Name: m.configMapName, Namespace: m.configMapNamespace}, m.buildDefaultConfigMap())
+	if err != nil {
+		return "", errorUtil.Wrapf(err, "failed to read job schedule config from configmap %s in namespace %s", m.configMapName, m.configMapNamespace)
+	}
+	raw, ok := cm.Data[providers.JobSchedulesConfigMapKey]
+	if !ok || raw == "" {
+		return defaultSchedule, nil
+	}
+	var schedules map[string]string
+	if err := json.Unmarshal([]byte(raw), &schedules); err != nil {
+		return "", errorUtil.Wrap(err, "failed to unmarshal job schedule config")
+	}
+	if schedule, ok := schedules[jobName]; ok {
+		return schedule, nil
+	}
+	return defaultSchedule, nil
+}
+
+func (m *ConfigMapConfigManager) buildDefaultConfigMap() *v1.ConfigMap {
+	return &v1.ConfigMap{
+		ObjectMeta: controllerruntime.ObjectMeta{
+			Name:      m.configMapName,
+			Namespace: m.configMapNamespace,
+		},
+		Data: providers.DefaultProviderConfigMapData(),
+	}
+}
@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+
+	"github.com/robfig/cron/v3"
+	errorUtil "github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Job is a named periodic task that re-asserts desired state for a provisioned cloud resource
+// against its cloud API (bucket tags, encryption, lifecycle rules, Postgres firewall rules, ...)
+// so drift introduced outside of a CR event is corrected without waiting for one
+type Job struct {
+	// Name uniquely identifies the job, e.g. "s3-bucket-policy-sync"
+	Name string
+	// Schedule is a standard cron expression, e.g. "@every 5m"
+	Schedule string
+	// Run re-asserts desired state. ctx is cancelled when the scheduler is stopped
+	Run func(ctx context.Context) error
+}
+
+//go:generate moq -out scheduler_moq.go . JobRegistry
+// JobRegistry lets providers register their own periodic sync jobs without depending on the
+// concrete Scheduler implementation
+type JobRegistry interface {
+	RegisterJob(job Job) error
+}
+
+var _ JobRegistry = (*Scheduler)(nil)
+
+// Scheduler runs registered Jobs on their configured cron schedule, built on robfig/cron.
+// RegisterJob is safe to call concurrently, since it's invoked from the reconcile path, where
+// controller-runtime commonly reconciles multiple CRs at once
+type Scheduler struct {
+	cron    *cron.Cron
+	logger  *logrus.Entry
+	mu      sync.Mutex
+	entries map[string]cron.EntryID
+}
+
+func NewScheduler(logger *logrus.Entry) *Scheduler {
+	return &Scheduler{
+		cron:    cron.New(),
+		logger:  logger.WithFields(logrus.Fields{"component": "scheduler"}),
+		entries: map[string]cron.EntryID{},
+	}
+}
+
+// RegisterJob schedules job to run on job.Schedule. Registering a job with a name that's
+// already scheduled replaces its previous entry, so a schedule reloaded from the
+// cloud-resource-config ConfigMap can change a job's frequency without an operator restart
+func (s *Scheduler) RegisterJob(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.entries[job.Name]; ok {
+		s.cron.Remove(existing)
+		delete(s.entries, job.Name)
+	}
+	name := job.Name
+	run := job.Run
+	id, err := s.cron.AddFunc(job.Schedule, func() {
+		s.logger.Infof("running scheduled job %s", name)
+		if err := run(context.Background()); err != nil {
+			s.logger.Errorf("scheduled job %s failed: %v", name, err)
+		}
+	})
+	if err != nil {
+		return errorUtil.Wrapf(err, "failed to schedule job %s with schedule %q", job.Name, job.Schedule)
+	}
+	s.entries[job.Name] = id
+	return nil
+}
+
+// Start begins running registered jobs on their schedules. It returns immediately; jobs run
+// until ctx is cancelled, at which point the scheduler waits for any in-flight run to finish
+func (s *Scheduler) Start(ctx context.Context) {
+	s.cron.Start()
+	go func() {
+		<-ctx.Done()
+		<-s.cron.Stop().Done()
+	}()
+}
+
+var (
+	defaultOnce      sync.Once
+	defaultScheduler *Scheduler
+)
+
+// Default returns the process-wide Scheduler that every provider registers its sync jobs
+// against, starting it for the lifetime of the process the first time it's requested. This
+// tree has no cmd/manager to construct and Start a Scheduler once at operator startup, so
+// providers share this lazily-started instance instead of each running their own cron loop
+func Default(logger *logrus.Entry) *Scheduler {
+	defaultOnce.Do(func() {
+		defaultScheduler = NewScheduler(logger)
+		defaultScheduler.Start(context.Background())
+	})
+	return defaultScheduler
+}
@@ -0,0 +1,27 @@
+package resources
+
+import (
+	"context"
+
+	errorUtil "github.com/pkg/errors"
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetConfigMapOrDefault returns the configmap at namespacedName, creating it from
+// defaultCM if it does not already exist
+func GetConfigMapOrDefault(ctx context.Context, c client.Client, namespacedName types.NamespacedName, defaultCM *v1.ConfigMap) (*v1.ConfigMap, error) {
+	cm := &v1.ConfigMap{}
+	if err := c.Get(ctx, namespacedName, cm); err != nil {
+		if !apiErrors.IsNotFound(err) {
+			return nil, errorUtil.Wrapf(err, "failed to get configmap %s in namespace %s", namespacedName.Name, namespacedName.Namespace)
+		}
+		if err := c.Create(ctx, defaultCM); err != nil {
+			return nil, errorUtil.Wrapf(err, "failed to create default configmap %s in namespace %s", namespacedName.Name, namespacedName.Namespace)
+		}
+		return defaultCM, nil
+	}
+	return cm, nil
+}
@@ -0,0 +1,26 @@
+package resources
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AddFinalizer adds finalizer to the list of object's finalizers if it is not already present
+func AddFinalizer(meta *metav1.ObjectMeta, finalizer string) {
+	for _, f := range meta.Finalizers {
+		if f == finalizer {
+			return
+		}
+	}
+	meta.Finalizers = append(meta.Finalizers, finalizer)
+}
+
+// RemoveFinalizer removes finalizer from the list of object's finalizers if it is present
+func RemoveFinalizer(meta *metav1.ObjectMeta, finalizer string) {
+	finalizers := meta.Finalizers[:0]
+	for _, f := range meta.Finalizers {
+		if f != finalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	meta.Finalizers = finalizers
+}
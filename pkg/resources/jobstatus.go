@@ -0,0 +1,24 @@
+package resources
+
+import (
+	"github.com/integr8ly/cloud-resource-operator/pkg/apis/integreatly/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SetJobStatusCondition records the outcome of a named periodic sync job run in status,
+// replacing any existing condition for that job name
+func SetJobStatusCondition(status *v1alpha1.ResourceTypeStatus, name string, success bool, message string) {
+	cond := v1alpha1.JobStatusCondition{
+		Name:        name,
+		LastRunTime: metav1.Now(),
+		Success:     success,
+		Message:     message,
+	}
+	for i, existing := range status.JobStatuses {
+		if existing.Name == name {
+			status.JobStatuses[i] = cond
+			return
+		}
+	}
+	status.JobStatuses = append(status.JobStatuses, cond)
+}
@@ -0,0 +1,22 @@
+package resources
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+const passwordAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// GeneratePassword returns a random 32-character string suitable for use as a
+// generated admin password on a provisioned database
+func GeneratePassword() (string, error) {
+	b := make([]byte, 32)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passwordAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		b[i] = passwordAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
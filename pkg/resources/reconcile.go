@@ -0,0 +1,25 @@
+package resources
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// envForcedReconcileTime lets an operator override every provider's reconcile
+// interval at once, primarily for local development and e2e tests
+const envForcedReconcileTime = "FORCED_RECONCILE_TIME_SECONDS"
+
+// GetForcedReconcileTimeOrDefault returns the reconcile interval a provider should
+// report from GetReconcileTime, honouring FORCED_RECONCILE_TIME_SECONDS if it is set
+func GetForcedReconcileTimeOrDefault(defaultTo time.Duration) time.Duration {
+	forced, exists := os.LookupEnv(envForcedReconcileTime)
+	if !exists {
+		return defaultTo
+	}
+	seconds, err := strconv.Atoi(forced)
+	if err != nil {
+		return defaultTo
+	}
+	return time.Second * time.Duration(seconds)
+}